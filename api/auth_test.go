@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/auth"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/reqctx"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenAuthenticator accepts tokens of the form "valid:<subject>".
+type tokenAuthenticator struct{}
+
+func (tokenAuthenticator) Authenticate(ctx context.Context, token string) (auth.Identity, error) {
+	var subject string
+	if _, err := fmt.Sscanf(token, "valid:%s", &subject); err != nil {
+		return auth.Identity{}, auth.ErrUnauthenticated
+	}
+	return auth.Identity{Subject: subject}, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(authenticate(tokenAuthenticator{}, []string{"/healthz"}))
+	router.GET("/healthz", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	router.GET("/orders", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, reqctx.Actor(ctx.Request.Context()))
+	})
+
+	testCases := []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+		body          string
+	}{
+		{name: "OK", path: "/orders", authorization: "Bearer valid:customer-1", status: http.StatusOK, body: "customer-1"},
+		{name: "LowercaseScheme", path: "/orders", authorization: "bearer valid:customer-1", status: http.StatusOK, body: "customer-1"},
+		{name: "NoToken", path: "/orders", status: http.StatusUnauthorized},
+		{name: "OtherScheme", path: "/orders", authorization: "Basic dXNlcjpwYXNz", status: http.StatusUnauthorized},
+		{name: "EmptyToken", path: "/orders", authorization: "Bearer ", status: http.StatusUnauthorized},
+		{name: "InvalidToken", path: "/orders", authorization: "Bearer forged", status: http.StatusUnauthorized},
+		{name: "PublicPath", path: "/healthz", status: http.StatusOK},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.authorization != "" {
+				req.Header.Set(authorizationHeader, tc.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tc.status, recorder.Code)
+			if tc.status == http.StatusUnauthorized {
+				require.Equal(t, bearerScheme, recorder.Header().Get("WWW-Authenticate"))
+				require.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), `"code":"unauthenticated"`)
+			}
+			if tc.body != "" {
+				require.Equal(t, tc.body, recorder.Body.String())
+			}
+		})
+	}
+}
+
+// keyAuthenticator accepts API keys of the form "sok_<scope>".
+type keyAuthenticator struct{}
+
+func (keyAuthenticator) Authenticate(ctx context.Context, token string) (auth.Identity, error) {
+	scope := entity.Scope(strings.TrimPrefix(token, auth.APIKeyPrefix))
+	if !auth.IsAPIKey(token) || !scope.IsValid() {
+		return auth.Identity{}, auth.ErrUnauthenticated
+	}
+	return auth.Identity{Subject: "api-key:1", Role: entity.RoleStaff, Scopes: []entity.Scope{scope}}, nil
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+
+	router := gin.New()
+	router.Use(authenticate(auth.NewChain(keyAuthenticator{}, tokenAuthenticator{}), nil))
+	router.GET("/orders", ok)
+	router.POST("/orders", ok)
+	router.DELETE("/orders/:id", ok)
+	router.DELETE("/orders/:id/items/:itemId", ok)
+	router.GET("/webhooks", ok)
+	router.DELETE("/webhooks/:id", ok)
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		value  string
+		status int
+	}{
+		{name: "ReadWithHeader", method: http.MethodGet, path: "/orders", header: apiKeyHeader, value: "sok_orders:read", status: http.StatusOK},
+		{name: "ReadWithBearer", method: http.MethodGet, path: "/orders", header: authorizationHeader, value: "Bearer sok_orders:read", status: http.StatusOK},
+		{name: "WriteNeedsWriteScope", method: http.MethodPost, path: "/orders", header: apiKeyHeader, value: "sok_orders:read", status: http.StatusForbidden},
+		{name: "WriteScopeGrantsRead", method: http.MethodGet, path: "/orders", header: apiKeyHeader, value: "sok_orders:write", status: http.StatusOK},
+		{name: "WriteScope", method: http.MethodPost, path: "/orders", header: apiKeyHeader, value: "sok_orders:write", status: http.StatusOK},
+		{name: "DeleteNeedsAdminScope", method: http.MethodDelete, path: "/orders/1", header: apiKeyHeader, value: "sok_orders:write", status: http.StatusForbidden},
+		{name: "AdminScope", method: http.MethodDelete, path: "/orders/1", header: apiKeyHeader, value: "sok_orders:admin", status: http.StatusOK},
+		{name: "ItemDeleteNeedsWriteScope", method: http.MethodDelete, path: "/orders/1/items/2", header: apiKeyHeader, value: "sok_orders:read", status: http.StatusForbidden},
+		{name: "ItemDeleteWithWriteScope", method: http.MethodDelete, path: "/orders/1/items/2", header: apiKeyHeader, value: "sok_orders:write", status: http.StatusOK},
+		{name: "WebhookDeleteNeedsAdminScope", method: http.MethodDelete, path: "/webhooks/1", header: apiKeyHeader, value: "sok_orders:write", status: http.StatusForbidden},
+		{name: "WebhooksNeedAdminScope", method: http.MethodGet, path: "/webhooks", header: apiKeyHeader, value: "sok_orders:write", status: http.StatusForbidden},
+		{name: "InvalidKey", method: http.MethodGet, path: "/orders", header: apiKeyHeader, value: "sok_unknown", status: http.StatusUnauthorized},
+		{name: "JWTInKeyHeader", method: http.MethodGet, path: "/orders", header: apiKeyHeader, value: "valid:customer-1", status: http.StatusUnauthorized},
+		{name: "UserNotLimitedByScope", method: http.MethodGet, path: "/webhooks", header: authorizationHeader, value: "Bearer valid:admin-1", status: http.StatusOK},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set(tc.header, tc.value)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tc.status, recorder.Code)
+			if tc.status == http.StatusForbidden {
+				require.Contains(t, recorder.Body.String(), `"code":"insufficient_scope"`)
+			}
+		})
+	}
+}
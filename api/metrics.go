@@ -0,0 +1,29 @@
+package api
+
+import (
+	"simple-order-go/internal/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so that scanners
+// can't create a series per path they try.
+const unmatchedRoute = "unmatched"
+
+// observe counts every request and how long it took by route and status.
+// Routes are labelled as registered, e.g. "/orders/:id".
+func observe(ctx *gin.Context) {
+	start := time.Now()
+	ctx.Next()
+
+	route := ctx.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+
+	labels := []string{ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())}
+	metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+}
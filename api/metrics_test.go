@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/metrics"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(observe)
+	router.GET("/orders/:id", func(ctx *gin.Context) { ctx.Status(http.StatusNotFound) })
+
+	found := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/orders/:id", "404")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")
+	foundBefore, unmatchedBefore := testutil.ToFloat64(found), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/wp-login.php"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Requests are labelled by route, not by path.
+	require.Equal(t, foundBefore+2, testutil.ToFloat64(found))
+	require.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+	require.Positive(t, testutil.CollectAndCount(metrics.HTTPRequestDuration))
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"simple-order-go/internal/handler"
+	"simple-order-go/internal/reqctx"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+	requestIDBytes     = 16
+)
+
+// requestID tags the request context with the client's X-Request-ID, or a
+// generated one if the client didn't send a usable one, and echoes it in the
+// response.
+func requestID(ctx *gin.Context) {
+	id := ctx.GetHeader(requestIDHeader)
+	if id == "" || len(id) > maxRequestIDLength {
+		id = newRequestID()
+	}
+
+	ctx.Header(requestIDHeader, id)
+	ctx.Request = ctx.Request.WithContext(reqctx.WithRequestID(ctx.Request.Context(), id))
+	ctx.Next()
+}
+
+// logRequests logs every request once it has been served. It runs after
+// requestID, so the line carries the request ID like every other line logged
+// while serving the request. Server errors are logged as errors and client
+// errors as warnings.
+func logRequests(ctx *gin.Context) {
+	start := time.Now()
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	level := slog.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= http.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", ctx.Request.Method),
+		slog.String("route", ctx.FullPath()),
+		slog.String("path", ctx.Request.URL.Path),
+		slog.Int("status", status),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("bytes", ctx.Writer.Size()),
+		slog.String("client_ip", ctx.ClientIP()),
+		slog.String("user_agent", ctx.Request.UserAgent()),
+	}
+	if len(ctx.Errors) > 0 {
+		attrs = append(attrs, slog.String("error", ctx.Errors.String()))
+	}
+
+	slog.LogAttrs(ctx.Request.Context(), level, "request", attrs...)
+}
+
+// recoverPanics turns a panic in a handler into a 500 problem response and
+// logs it with its stack.
+var recoverPanics = gin.CustomRecoveryWithWriter(io.Discard, func(ctx *gin.Context, recovered any) {
+	slog.ErrorContext(ctx.Request.Context(), "panic serving request",
+		"panic", fmt.Sprint(recovered),
+		"stack", string(debug.Stack()),
+	)
+	handler.AbortWithError(ctx, fmt.Errorf("panic: %v", recovered))
+})
+
+func newRequestID() string {
+	b := make([]byte, requestIDBytes)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// queryTimeout puts a deadline on the request context, which the handlers
+// pass down to the database, so a slow query fails the request instead of
+// holding a connection indefinitely.
+func queryTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if timeout <= 0 {
+			ctx.Next()
+			return
+		}
+
+		c, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+
+		ctx.Request = ctx.Request.WithContext(c)
+		ctx.Next()
+	}
+}
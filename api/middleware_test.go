@@ -0,0 +1,61 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/logging"
+	"simple-order-go/pkg/config"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, config.Log{Level: "info"})
+	require.NoError(t, err)
+
+	defaultLogger := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(defaultLogger)
+
+	router := gin.New()
+	router.Use(requestID, logRequests, recoverPanics)
+	router.GET("/orders/:id", func(ctx *gin.Context) { panic("nil order") })
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set(requestIDHeader, "req-1")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	require.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	require.Equal(t, "req-1", recorder.Header().Get(requestIDHeader))
+
+	var lines []map[string]interface{}
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var line map[string]interface{}
+		require.NoError(t, decoder.Decode(&line))
+		lines = append(lines, line)
+	}
+
+	require.Equal(t, 2, len(lines))
+	require.Equal(t, "panic serving request", lines[0]["msg"])
+	require.Equal(t, "nil order", lines[0]["panic"])
+
+	request := lines[1]
+	require.Equal(t, "request", request["msg"])
+	require.Equal(t, "ERROR", request["level"])
+	require.Equal(t, "req-1", request["request_id"])
+	require.Equal(t, "/orders/:id", request["route"])
+	require.Equal(t, float64(http.StatusInternalServerError), request["status"])
+	require.Contains(t, request["error"], "nil order")
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"log/slog"
+	"math"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/handler"
+	"simple-order-go/internal/ratelimit"
+	"simple-order-go/internal/reqctx"
+	"simple-order-go/pkg/config"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultRoute = "default"
+
+var errRateLimited = apperror.TooManyRequests("rate_limited", "too many requests, retry later")
+
+// rateLimit takes a token from the caller's bucket for the route, answering
+// with 429 when there is none left. It runs after authenticate, so callers
+// are told apart by their API key or user if they have one and by their IP
+// address otherwise. Responses carry RateLimit-* headers describing the
+// bucket. If the limiter fails, requests are let through.
+func rateLimit(limiter ratelimit.Limiter, cfg config.RateLimit) gin.HandlerFunc {
+	routes := make(map[string]config.Rate, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		routes[route.Method+" "+route.Path] = route.Rate
+	}
+
+	return func(ctx *gin.Context) {
+		route := ctx.Request.Method + " " + ctx.FullPath()
+		rate, ok := routes[route]
+		if !ok {
+			route, rate = defaultRoute, cfg.Default
+		}
+
+		take(ctx, limiter, clientKey(ctx)+"|"+route, rate)
+	}
+}
+
+// rateLimitByIP takes a token from the bucket of the caller's IP address,
+// shared by all routes. It runs before authenticate, so that requests with
+// wrong credentials, which rateLimit never sees, are limited too.
+func rateLimitByIP(limiter ratelimit.Limiter, rate config.Rate) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		take(ctx, limiter, "ip:"+ctx.ClientIP(), rate)
+	}
+}
+
+// take takes a token from the bucket under key and goes on with the request,
+// or aborts it with 429 if the bucket is empty.
+func take(ctx *gin.Context, limiter ratelimit.Limiter, key string, rate config.Rate) {
+	limit := ratelimit.Limit{Requests: rate.Requests, Period: rate.Period, Burst: rate.Burst}
+	if limit.IsZero() {
+		ctx.Next()
+		return
+	}
+
+	result, err := limiter.Allow(ctx.Request.Context(), key, limit)
+	if err != nil {
+		slog.WarnContext(ctx.Request.Context(), "rate limiter failed, letting request through", "error", err)
+		ctx.Next()
+		return
+	}
+
+	ctx.Header("RateLimit-Policy", rateLimitPolicy(limit))
+	ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	ctx.Header("RateLimit-Reset", seconds(result.Reset))
+
+	if !result.Allowed {
+		ctx.Header("Retry-After", seconds(result.RetryAfter))
+		handler.AbortWithError(ctx, errRateLimited)
+		return
+	}
+
+	ctx.Next()
+}
+
+// clientKey identifies the caller of a request. Client IP addresses are only
+// taken from X-Forwarded-For for requests from trusted proxies.
+func clientKey(ctx *gin.Context) string {
+	if actor := reqctx.Actor(ctx.Request.Context()); actor != "" {
+		return "sub:" + actor
+	}
+	return "ip:" + ctx.ClientIP()
+}
+
+// rateLimitPolicy describes limit the way the RateLimit-Policy header does,
+// e.g. "60;w=60;burst=10".
+func rateLimitPolicy(limit ratelimit.Limit) string {
+	policy := strconv.Itoa(limit.Requests) + ";w=" + seconds(limit.Period)
+	if limit.Burst > 0 {
+		policy += ";burst=" + strconv.Itoa(limit.Burst)
+	}
+	return policy
+}
+
+// seconds rounds d up to whole seconds, so clients that wait that long are
+// never early.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/ratelimit"
+	"simple-order-go/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// failingLimiter stands in for a shared store that is down.
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func newRateLimitedRouter(limiter ratelimit.Limiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+
+	router := gin.New()
+	_ = router.SetTrustedProxies(nil)
+	router.Use(rateLimitByIP(limiter, config.Rate{Requests: 60, Period: time.Minute, Burst: 10}))
+	router.Use(authenticate(tokenAuthenticator{}, []string{"/healthz"}))
+	router.Use(rateLimit(limiter, config.RateLimit{
+		Default: config.Rate{Requests: 60, Period: time.Minute, Burst: 2},
+		Routes: []config.RouteRate{
+			{Method: http.MethodPost, Path: "/orders", Rate: config.Rate{Requests: 1, Period: time.Minute}},
+		},
+	}))
+	router.GET("/healthz", ok)
+	router.GET("/orders", ok)
+	router.GET("/orders/:id", ok)
+	router.POST("/orders", ok)
+	return router
+}
+
+func serve(router *gin.Engine, method string, path string, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if token != "" {
+		req.Header.Set(authorizationHeader, "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimit(t *testing.T) {
+	router := newRateLimitedRouter(ratelimit.NewMemoryLimiter())
+
+	recorder := serve(router, http.MethodGet, "/orders", "valid:customer-1")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "1", recorder.Header().Get("RateLimit-Reset"))
+	require.Equal(t, "60;w=60;burst=2", recorder.Header().Get("RateLimit-Policy"))
+
+	// Routes without a limit of their own share the default bucket.
+	recorder = serve(router, http.MethodGet, "/orders/1", "valid:customer-1")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+
+	recorder = serve(router, http.MethodGet, "/orders", "valid:customer-1")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	require.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	require.Contains(t, recorder.Body.String(), `"code":"rate_limited"`)
+
+	// Routes with a limit of their own have a bucket of their own.
+	recorder = serve(router, http.MethodPost, "/orders", "valid:customer-1")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "1;w=60", recorder.Header().Get("RateLimit-Policy"))
+
+	recorder = serve(router, http.MethodPost, "/orders", "valid:customer-1")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+
+	// Other users from the same address are limited separately.
+	recorder = serve(router, http.MethodGet, "/orders", "valid:customer-2")
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	router := newRateLimitedRouter(ratelimit.NewMemoryLimiter())
+
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/healthz", "").Code)
+	}
+	require.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodGet, "/healthz", "").Code)
+}
+
+func TestRateLimitFailedAuthentication(t *testing.T) {
+	router := newRateLimitedRouter(ratelimit.NewMemoryLimiter())
+
+	for i := 0; i < 10; i++ {
+		require.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "/orders", "invalid").Code)
+	}
+
+	recorder := serve(router, http.MethodGet, "/orders", "invalid")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "10", recorder.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitIgnoresForwardedFor(t *testing.T) {
+	router := newRateLimitedRouter(ratelimit.NewMemoryLimiter())
+
+	// The client isn't a trusted proxy, so X-Forwarded-For doesn't give it a
+	// fresh bucket.
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		if i < 2 {
+			require.Equal(t, http.StatusOK, recorder.Code)
+		} else {
+			require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		}
+	}
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	router := newRateLimitedRouter(failingLimiter{})
+
+	recorder := serve(router, http.MethodGet, "/orders", "valid:customer-1")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+}
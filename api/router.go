@@ -1,34 +1,148 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"simple-order-go/internal/auth"
 	"simple-order-go/internal/handler"
+	"simple-order-go/internal/ratelimit"
+	"simple-order-go/pkg/config"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Server struct {
-	router       *gin.Engine
-	orderHandler handler.OrderHandler
+	config             config.Config
+	router             *gin.Engine
+	httpServer         *http.Server
+	authenticator      auth.Authenticator
+	limiter            ratelimit.Limiter
+	orderHandler       handler.OrderHandler
+	itemHandler        handler.ItemHandler
+	idempotencyHandler handler.IdempotencyHandler
+	webhookHandler     handler.WebhookHandler
+	apiKeyHandler      handler.APIKeyHandler
+	healthHandler      handler.HealthHandler
 }
 
-func NewServer(orderHandler handler.OrderHandler) *Server {
-	server := &Server{orderHandler: orderHandler}
-	server.setupRouter()
-	return server
+func NewServer(
+	cfg config.Config,
+	authenticator auth.Authenticator,
+	limiter ratelimit.Limiter,
+	orderHandler handler.OrderHandler,
+	itemHandler handler.ItemHandler,
+	idempotencyHandler handler.IdempotencyHandler,
+	webhookHandler handler.WebhookHandler,
+	apiKeyHandler handler.APIKeyHandler,
+	healthHandler handler.HealthHandler,
+) (*Server, error) {
+	server := &Server{
+		config:             cfg,
+		authenticator:      authenticator,
+		limiter:            limiter,
+		orderHandler:       orderHandler,
+		itemHandler:        itemHandler,
+		idempotencyHandler: idempotencyHandler,
+		webhookHandler:     webhookHandler,
+		apiKeyHandler:      apiKeyHandler,
+		healthHandler:      healthHandler,
+	}
+	if err := server.setupRouter(); err != nil {
+		return nil, err
+	}
+	server.setupHTTPServer()
+	return server, nil
 }
 
-func (server *Server) setupRouter() {
-	router := gin.Default()
+func (server *Server) setupRouter() error {
+	router := gin.New()
+	// Client IPs, which rate limits and logs rely on, are only taken from
+	// X-Forwarded-For when the request comes from a trusted proxy.
+	if err := router.SetTrustedProxies(server.config.App.TrustedProxies); err != nil {
+		return fmt.Errorf("trusted proxies: %w", err)
+	}
 
-	router.POST("/orders", server.orderHandler.CreateOrder)
+	router.Use(otelgin.Middleware(server.config.Tracing.ServiceName))
+	router.Use(observe, requestID, logRequests, recoverPanics, queryTimeout(server.config.Database.QueryTimeout))
+	if server.limiter != nil {
+		router.Use(rateLimitByIP(server.limiter, server.config.RateLimit.PerIP))
+	}
+	if server.authenticator != nil {
+		router.Use(authenticate(server.authenticator, server.config.Auth.PublicPaths))
+	}
+	if server.limiter != nil {
+		router.Use(rateLimit(server.limiter, server.config.RateLimit))
+	}
+
+	router.GET("/healthz", server.healthHandler.Live)
+	router.GET("/readyz", server.healthHandler.Ready)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	router.POST("/orders", server.idempotencyHandler.Idempotent, server.orderHandler.CreateOrder)
 	router.GET("/orders", server.orderHandler.GetAllOrders)
 	router.GET("/orders/:id", server.orderHandler.GetOrderByID)
 	router.PUT("/orders/:id", server.orderHandler.UpdateOrder)
+	router.PATCH("/orders/:id", server.orderHandler.PatchOrder)
 	router.DELETE("/orders/:id", server.orderHandler.DeleteOrder)
+	router.POST("/orders/:id/restore", server.orderHandler.RestoreOrder)
+	router.POST("/orders/:id/transitions", server.orderHandler.TransitionOrder)
+	router.GET("/orders/:id/transitions", server.orderHandler.GetOrderTransitions)
+	router.GET("/orders/:id/history", server.orderHandler.GetOrderHistory)
+
+	router.GET("/orders/:id/items", server.itemHandler.GetItems)
+	router.POST("/orders/:id/items", server.itemHandler.CreateItem)
+	router.GET("/orders/:id/items/:itemId", server.itemHandler.GetItem)
+	router.PATCH("/orders/:id/items/:itemId", server.itemHandler.UpdateItem)
+	router.DELETE("/orders/:id/items/:itemId", server.itemHandler.DeleteItem)
+
+	router.POST("/webhooks", server.webhookHandler.CreateWebhook)
+	router.GET("/webhooks", server.webhookHandler.GetWebhooks)
+	router.GET("/webhooks/:id", server.webhookHandler.GetWebhook)
+	router.DELETE("/webhooks/:id", server.webhookHandler.DeleteWebhook)
+	router.GET("/webhooks/:id/deliveries", server.webhookHandler.GetDeliveries)
+	router.GET("/webhooks/:id/deliveries/:deliveryId", server.webhookHandler.GetDelivery)
+	router.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", server.webhookHandler.RedeliverDelivery)
+
+	router.POST("/api-keys", server.apiKeyHandler.CreateAPIKey)
+	router.GET("/api-keys", server.apiKeyHandler.GetAPIKeys)
+	router.POST("/api-keys/:id/rotate", server.apiKeyHandler.RotateAPIKey)
+	router.DELETE("/api-keys/:id", server.apiKeyHandler.RevokeAPIKey)
+
+	admin := router.Group("/admin")
+	admin.POST("/orders/purge", server.orderHandler.PurgeOrders)
 
 	server.router = router
+	return nil
+}
+
+func (server *Server) setupHTTPServer() {
+	app := server.config.App
+	server.httpServer = &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", app.Host, app.Port),
+		Handler:           server.router,
+		ReadTimeout:       app.ReadTimeout,
+		ReadHeaderTimeout: app.ReadHeaderTimeout,
+		WriteTimeout:      app.WriteTimeout,
+		IdleTimeout:       app.IdleTimeout,
+		MaxHeaderBytes:    app.MaxHeaderBytes,
+	}
+}
+
+// Start serves the API until Shutdown is called, after which it returns nil.
+func (server *Server) Start() error {
+	err := server.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-func (server *Server) Start(port string) error {
-	return server.router.Run(port)
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish, or for ctx to be done.
+func (server *Server) Shutdown(ctx context.Context) error {
+	return server.httpServer.Shutdown(ctx)
 }
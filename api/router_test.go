@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"simple-order-go/internal/handler"
+	"simple-order-go/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestServerShutdownDrainsRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Config{App: config.App{
+		Host:              "127.0.0.1",
+		Port:              freePort(t),
+		ReadHeaderTimeout: time.Second,
+		MaxHeaderBytes:    4096,
+	}}
+	server, err := NewServer(cfg, nil, nil, handler.OrderHandler{}, handler.ItemHandler{}, handler.IdempotencyHandler{}, handler.WebhookHandler{}, handler.APIKeyHandler{}, handler.HealthHandler{})
+	require.NoError(t, err)
+	require.Equal(t, 4096, server.httpServer.MaxHeaderBytes)
+
+	started := make(chan struct{})
+	server.router.GET("/slow", func(ctx *gin.Context) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		ctx.Status(http.StatusOK)
+	})
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start()
+	}()
+
+	url := fmt.Sprintf("http://%s/slow", server.httpServer.Addr)
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", server.httpServer.Addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+
+	<-started
+	require.NoError(t, server.Shutdown(context.Background()))
+	require.Equal(t, http.StatusOK, <-status)
+	require.NoError(t, <-serverErr)
+
+	_, err = http.Get(url)
+	require.Error(t, err)
+}
+
+func TestServerRejectsInvalidTrustedProxies(t *testing.T) {
+	cfg := config.Config{App: config.App{TrustedProxies: []string{"not-an-ip"}}}
+	_, err := NewServer(cfg, nil, nil, handler.OrderHandler{}, handler.ItemHandler{}, handler.IdempotencyHandler{}, handler.WebhookHandler{}, handler.APIKeyHandler{}, handler.HealthHandler{})
+	require.Error(t, err)
+}
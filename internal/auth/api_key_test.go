@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"simple-order-go/pkg/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errKeyNotFound = apperror.NotFound("api_key_not_found", "api key not found")
+
+// memoryKeyStore keeps the active API keys by hash.
+type memoryKeyStore struct {
+	keys    map[string]entity.APIKey
+	touched map[int64]time.Time
+}
+
+func (s *memoryKeyStore) GetKeyByHash(ctx context.Context, hash string) (entity.APIKey, error) {
+	key, ok := s.keys[hash]
+	if !ok {
+		return entity.APIKey{}, errKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *memoryKeyStore) TouchKey(ctx context.Context, keyID int64, usedAt time.Time, precision time.Duration) error {
+	s.touched[keyID] = usedAt
+	return nil
+}
+
+func TestNewAPIKey(t *testing.T) {
+	key, prefix, hash, err := NewAPIKey()
+	require.NoError(t, err)
+	require.True(t, IsAPIKey(key))
+	require.True(t, strings.HasPrefix(key, prefix))
+	require.Len(t, prefix, displayedPrefix)
+	require.Equal(t, HashAPIKey(key), hash)
+	require.NotContains(t, hash, key)
+
+	other, _, _, err := NewAPIKey()
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	writer, _, writerHash, err := NewAPIKey()
+	require.NoError(t, err)
+
+	admin, _, adminHash, err := NewAPIKey()
+	require.NoError(t, err)
+
+	store := &memoryKeyStore{
+		keys: map[string]entity.APIKey{
+			writerHash: {ID: 1, Scopes: []entity.Scope{entity.ScopeOrdersWrite}},
+			adminHash:  {ID: 2, Scopes: []entity.Scope{entity.ScopeOrdersAdmin}},
+		},
+		touched: map[int64]time.Time{},
+	}
+	authenticator := NewAPIKeyAuthenticator(store)
+
+	identity, err := authenticator.Authenticate(context.Background(), writer)
+	require.NoError(t, err)
+	require.Equal(t, "api-key:1", identity.Subject)
+	require.Equal(t, entity.RoleStaff, identity.Role)
+	require.True(t, identity.IsAPIKey())
+	require.True(t, identity.HasScope(entity.ScopeOrdersRead))
+	require.False(t, identity.HasScope(entity.ScopeOrdersAdmin))
+	require.Contains(t, store.touched, int64(1))
+
+	identity, err = authenticator.Authenticate(context.Background(), admin)
+	require.NoError(t, err)
+	require.Equal(t, entity.RoleAdmin, identity.Role)
+
+	unknown, _, _, err := NewAPIKey()
+	require.NoError(t, err)
+
+	_, err = authenticator.Authenticate(context.Background(), unknown)
+	require.ErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = authenticator.Authenticate(context.Background(), "not-a-key")
+	require.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestChain(t *testing.T) {
+	verifier, err := NewJWTVerifier(config.Auth{Algorithm: "HS256", Secret: testSecret})
+	require.NoError(t, err)
+
+	key, _, hash, err := NewAPIKey()
+	require.NoError(t, err)
+
+	store := &memoryKeyStore{
+		keys:    map[string]entity.APIKey{hash: {ID: 1, Scopes: []entity.Scope{entity.ScopeOrdersRead}}},
+		touched: map[int64]time.Time{},
+	}
+	chain := NewChain(NewAPIKeyAuthenticator(store), verifier)
+
+	identity, err := chain.Authenticate(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, "api-key:1", identity.Subject)
+
+	identity, err = chain.Authenticate(context.Background(), signHS256(t, validClaims(), testSecret))
+	require.NoError(t, err)
+	require.Equal(t, "customer-1", identity.Subject)
+	require.False(t, identity.IsAPIKey())
+
+	_, err = NewChain(nil, verifier).Authenticate(context.Background(), key)
+	require.ErrorIs(t, err, ErrUnauthenticated)
+}
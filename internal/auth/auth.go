@@ -0,0 +1,51 @@
+// Package auth works out who sent a request from the credentials it carries.
+package auth
+
+import (
+	"context"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+)
+
+var (
+	ErrUnauthenticated   = apperror.Unauthenticated("unauthenticated", "missing or invalid credentials")
+	ErrInsufficientScope = apperror.Forbidden("insufficient_scope", "the api key lacks the scope this request needs")
+)
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	Subject string
+	Role    entity.Role
+	// Scopes limits what an API key may do. It is nil for users, whose
+	// access only depends on Role.
+	Scopes []entity.Scope
+}
+
+// IsAPIKey reports whether the caller authenticated with an API key.
+func (i Identity) IsAPIKey() bool {
+	return i.Scopes != nil
+}
+
+// HasScope reports whether the caller may do what scope allows. Users are
+// only limited by their role, so it is always true for them.
+func (i Identity) HasScope(scope entity.Scope) bool {
+	if !i.IsAPIKey() {
+		return true
+	}
+	return entity.APIKey{Scopes: i.Scopes}.HasScope(scope)
+}
+
+// roleOrCustomer returns role if it is known. Anything else gets the least
+// privileged role.
+func roleOrCustomer(role string) entity.Role {
+	if r := entity.Role(role); r.IsValid() {
+		return r
+	}
+	return entity.RoleCustomer
+}
+
+// Authenticator checks the bearer token of a request. It fails with an
+// error wrapping ErrUnauthenticated if the token isn't valid.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (Identity, error)
+}
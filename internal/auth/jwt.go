@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"simple-order-go/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTVerifier authenticates requests by JWTs signed with a single algorithm.
+// Tokens must carry a subject and an expiry. The caller's role is taken from
+// the "role" claim; tokens without a known role belong to customers.
+type JWTVerifier struct {
+	parser  *jwt.Parser
+	keyfunc jwt.Keyfunc
+}
+
+// NewJWTVerifier returns a verifier for the tokens cfg describes. The key is
+// read from cfg.JWKSFile if it is set, and from cfg.Secret or
+// cfg.PublicKeyFile, depending on the algorithm, otherwise.
+func NewJWTVerifier(cfg config.Auth) (*JWTVerifier, error) {
+	method := jwt.GetSigningMethod(cfg.Algorithm)
+	if method != jwt.SigningMethodHS256 && method != jwt.SigningMethodRS256 {
+		return nil, fmt.Errorf("auth: unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	keyfunc, err := newKeyfunc(cfg, method)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{method.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &JWTVerifier{parser: jwt.NewParser(opts...), keyfunc: keyfunc}, nil
+}
+
+func newKeyfunc(cfg config.Auth, method jwt.SigningMethod) (jwt.Keyfunc, error) {
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		return keys.keyfunc, nil
+	}
+
+	var key interface{}
+	switch method {
+	case jwt.SigningMethodHS256:
+		if cfg.Secret == "" {
+			return nil, errors.New("auth: HS256 needs a secret")
+		}
+		key = []byte(cfg.Secret)
+	case jwt.SigningMethodRS256:
+		if cfg.PublicKeyFile == "" {
+			return nil, errors.New("auth: RS256 needs a public_key_file or jwks_file")
+		}
+
+		data, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth: read public key: %w", err)
+		}
+
+		key, err = jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("auth: parse public key: %w", err)
+		}
+	}
+
+	return func(*jwt.Token) (interface{}, error) { return key, nil }, nil
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role"`
+}
+
+func (v *JWTVerifier) Authenticate(ctx context.Context, token string) (Identity, error) {
+	var claims claims
+	if _, err := v.parser.ParseWithClaims(token, &claims, v.keyfunc); err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	if claims.Subject == "" {
+		return Identity{}, fmt.Errorf("%w: token has no subject", ErrUnauthenticated)
+	}
+
+	return Identity{Subject: claims.Subject, Role: roleOrCustomer(claims.Role)}, nil
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"simple-order-go/internal/entity"
+	"simple-order-go/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "customer-1",
+		Issuer:    "https://auth.example.com",
+		Audience:  jwt.ClaimStrings{"orders"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func signHS256(t *testing.T, claims jwt.Claims, secret string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func signRS256(t *testing.T, claims jwt.Claims, key *rsa.PrivateKey, kid string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestHS256(t *testing.T) {
+	verifier, err := NewJWTVerifier(config.Auth{
+		Algorithm: "HS256",
+		Secret:    testSecret,
+		Issuer:    "https://auth.example.com",
+		Audience:  "orders",
+	})
+	require.NoError(t, err)
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	noExpiry := validClaims()
+	noExpiry.ExpiresAt = nil
+
+	noSubject := validClaims()
+	noSubject.Subject = ""
+
+	otherIssuer := validClaims()
+	otherIssuer.Issuer = "https://evil.example.com"
+
+	otherAudience := validClaims()
+	otherAudience.Audience = jwt.ClaimStrings{"billing"}
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{name: "OK", token: signHS256(t, validClaims(), testSecret), ok: true},
+		{name: "WrongSecret", token: signHS256(t, validClaims(), "another secret")},
+		{name: "Expired", token: signHS256(t, expired, testSecret)},
+		{name: "NoExpiry", token: signHS256(t, noExpiry, testSecret)},
+		{name: "NoSubject", token: signHS256(t, noSubject, testSecret)},
+		{name: "OtherIssuer", token: signHS256(t, otherIssuer, testSecret)},
+		{name: "OtherAudience", token: signHS256(t, otherAudience, testSecret)},
+		{name: "Unsigned", token: unsigned},
+		{name: "Malformed", token: "not-a-token"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			identity, err := verifier.Authenticate(context.Background(), tc.token)
+			if tc.ok {
+				require.NoError(t, err)
+				require.Equal(t, "customer-1", identity.Subject)
+				return
+			}
+			require.ErrorIs(t, err, ErrUnauthenticated)
+		})
+	}
+}
+
+func TestRoleClaim(t *testing.T) {
+	verifier, err := NewJWTVerifier(config.Auth{Algorithm: "HS256", Secret: testSecret})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		claim string
+		want  entity.Role
+	}{
+		{claim: "admin", want: entity.RoleAdmin},
+		{claim: "staff", want: entity.RoleStaff},
+		{claim: "", want: entity.RoleCustomer},
+		{claim: "superuser", want: entity.RoleCustomer},
+	}
+
+	for _, tc := range testCases {
+		token := signHS256(t, claims{RegisteredClaims: validClaims(), Role: tc.claim}, testSecret)
+
+		identity, err := verifier.Authenticate(context.Background(), token)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, identity.Role)
+	}
+}
+
+func TestRS256PublicKeyFile(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "public.pem")
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600)
+	require.NoError(t, err)
+
+	verifier, err := NewJWTVerifier(config.Auth{Algorithm: "RS256", PublicKeyFile: path})
+	require.NoError(t, err)
+
+	identity, err := verifier.Authenticate(context.Background(), signRS256(t, validClaims(), key, ""))
+	require.NoError(t, err)
+	require.Equal(t, "customer-1", identity.Subject)
+
+	// An HS256 token "signed" with the public key must not pass.
+	_, err = verifier.Authenticate(context.Background(), signHS256(t, validClaims(), string(der)))
+	require.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestRS256JWKSFile(t *testing.T) {
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	previous, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	unknown, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	set := jwks{Keys: []jwk{rsaJWK("current", &current.PublicKey), rsaJWK("previous", &previous.PublicKey)}}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	verifier, err := NewJWTVerifier(config.Auth{Algorithm: "RS256", JWKSFile: path})
+	require.NoError(t, err)
+
+	_, err = verifier.Authenticate(context.Background(), signRS256(t, validClaims(), current, "current"))
+	require.NoError(t, err)
+
+	_, err = verifier.Authenticate(context.Background(), signRS256(t, validClaims(), previous, "previous"))
+	require.NoError(t, err)
+
+	_, err = verifier.Authenticate(context.Background(), signRS256(t, validClaims(), current, "previous"))
+	require.ErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = verifier.Authenticate(context.Background(), signRS256(t, validClaims(), unknown, "unknown"))
+	require.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestNewJWTVerifierConfig(t *testing.T) {
+	_, err := NewJWTVerifier(config.Auth{Algorithm: "none", Secret: testSecret})
+	require.Error(t, err)
+
+	_, err = NewJWTVerifier(config.Auth{Algorithm: "HS256"})
+	require.Error(t, err)
+
+	_, err = NewJWTVerifier(config.Auth{Algorithm: "RS256"})
+	require.Error(t, err)
+
+	_, err = NewJWTVerifier(config.Auth{Algorithm: "RS256", JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	require.Error(t, err)
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jwk {
+	return jwk{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
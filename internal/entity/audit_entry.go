@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreated       AuditAction = "created"
+	AuditActionUpdated       AuditAction = "updated"
+	AuditActionDeleted       AuditAction = "deleted"
+	AuditActionRestored      AuditAction = "restored"
+	AuditActionPurged        AuditAction = "purged"
+	AuditActionStatusChanged AuditAction = "status_changed"
+	AuditActionItemAdded     AuditAction = "item_added"
+	AuditActionItemUpdated   AuditAction = "item_updated"
+	AuditActionItemDeleted   AuditAction = "item_deleted"
+)
+
+// AuditEntry records a change to an order: who made it, in which request,
+// and how the order's view model changed. Entries are never updated.
+type AuditEntry struct {
+	ID        int64           `gorm:"primary_key;column:id;autoIncrement"`
+	OrderID   int64           `gorm:"index;column:order_id"`
+	Action    AuditAction     `gorm:"column:action"`
+	Actor     string          `gorm:"column:actor"`
+	RequestID string          `gorm:"column:request_id"`
+	Changes   json.RawMessage `gorm:"column:changes;type:jsonb"`
+	CreatedAt time.Time       `gorm:"column:created_at;autoCreateTime"`
+}
+
+// AuditChange is a value that changed, addressed by its path in the order's
+// JSON representation, e.g. "items[id=12].quantity". From is null for added
+// values and To is null for removed ones.
+type AuditChange struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type AuditEntryViewModel struct {
+	ID        int64           `json:"id"`
+	Action    AuditAction     `json:"action"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id,omitempty"`
+	Changes   json.RawMessage `json:"changes"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (e AuditEntry) ToViewModel() AuditEntryViewModel {
+	return AuditEntryViewModel{
+		ID:        e.ID,
+		Action:    e.Action,
+		Actor:     e.Actor,
+		RequestID: e.RequestID,
+		Changes:   e.Changes,
+		CreatedAt: e.CreatedAt,
+	}
+}
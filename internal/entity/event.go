@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventOrderCreated       EventType = "order.created"
+	EventOrderUpdated       EventType = "order.updated"
+	EventOrderDeleted       EventType = "order.deleted"
+	EventOrderRestored      EventType = "order.restored"
+	EventOrderStatusChanged EventType = "order.status_changed"
+	EventItemAdded          EventType = "item.added"
+	EventItemUpdated        EventType = "item.updated"
+	EventItemDeleted        EventType = "item.deleted"
+)
+
+// OutboxEvent is a domain event waiting in the outbox to be published. It is
+// written in the transaction that made the change, and is published at least
+// once after that transaction commits.
+type OutboxEvent struct {
+	ID          int64           `gorm:"primary_key;column:id;autoIncrement"`
+	OrderID     int64           `gorm:"index;column:order_id"`
+	Type        EventType       `gorm:"column:type"`
+	Payload     json.RawMessage `gorm:"column:payload;type:jsonb"`
+	Attempts    int             `gorm:"column:attempts"`
+	LastError   string          `gorm:"column:last_error"`
+	AvailableAt time.Time       `gorm:"column:available_at"`
+	PublishedAt *time.Time      `gorm:"column:published_at"`
+	CreatedAt   time.Time       `gorm:"column:created_at;autoCreateTime"`
+}
+
+// Event is the published form of an OutboxEvent. Consumers can use ID to
+// drop the duplicates that at-least-once delivery may produce.
+type Event struct {
+	ID         int64           `json:"id"`
+	Type       EventType       `json:"type"`
+	OrderID    int64           `json:"order_id"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+func (e OutboxEvent) ToEvent() Event {
+	return Event{
+		ID:         e.ID,
+		Type:       e.Type,
+		OrderID:    e.OrderID,
+		Data:       e.Payload,
+		OccurredAt: e.CreatedAt,
+	}
+}
+
+// OrderDeletedData is the data of an order.deleted event.
+type OrderDeletedData struct {
+	ID int64 `json:"id"`
+}
+
+// OrderStatusChangedData is the data of an order.status_changed event.
+type OrderStatusChangedData struct {
+	ID   int64       `json:"id"`
+	From OrderStatus `json:"from"`
+	To   OrderStatus `json:"to"`
+}
+
+// ItemDeletedData is the data of an item.deleted event.
+type ItemDeletedData struct {
+	ID int64 `json:"id"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+)
+
+// IdempotencyKey remembers the request a client sent under a key and, once
+// the request has been handled, the response that was returned for it. Keys
+// belong to the subject that sent the request; different owners can use the
+// same key. A key in progress is locked until LockedUntil by the request
+// holding LockToken, and every key is forgotten after ExpiresAt.
+type IdempotencyKey struct {
+	Owner        string    `gorm:"primary_key;column:owner"`
+	Key          string    `gorm:"primary_key;column:key"`
+	Fingerprint  string    `gorm:"column:fingerprint"`
+	StatusCode   int       `gorm:"column:status_code"`
+	ResponseBody []byte    `gorm:"column:response_body"`
+	LockedUntil  time.Time `gorm:"column:locked_until"`
+	LockToken    string    `gorm:"column:lock_token"`
+	ExpiresAt    time.Time `gorm:"column:expires_at;index"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (e IdempotencyKey) Completed() bool {
+	return e.StatusCode != 0
+}
@@ -2,6 +2,9 @@ package entity
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type Items []Item
@@ -9,13 +12,17 @@ type Items []Item
 type ItemViewModels []ItemViewModel
 
 type Item struct {
-	ID          int64     `gorm:"primary_key;column:id;autoIncrement"`
-	Name        string    `gorm:"column:name"`
-	Description string    `gorm:"index;column:description"`
-	Quantity    int32     `gorm:"column:quantity"`
-	OrderID     int64     `gorm:"index;column:order_id"`
-	UpdatedAt   time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
-	CreatedAt   time.Time `gorm:"column:updated_at;autoCreateTime"`
+	ID          int64           `gorm:"primary_key;column:id;autoIncrement"`
+	Name        string          `gorm:"column:name"`
+	Description string          `gorm:"index;column:description"`
+	Quantity    int32           `gorm:"column:quantity"`
+	UnitPrice   decimal.Decimal `gorm:"column:unit_price;type:numeric(19,4)"`
+	Currency    string          `gorm:"column:currency"`
+	LineTotal   decimal.Decimal `gorm:"column:line_total;type:numeric(19,4)"`
+	OrderID     int64           `gorm:"index;column:order_id"`
+	UpdatedAt   time.Time       `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	CreatedAt   time.Time       `gorm:"column:created_at;autoCreateTime"`
+	DeletedAt   gorm.DeletedAt  `gorm:"column:deleted_at;index"`
 }
 
 type ItemViewModel struct {
@@ -23,33 +30,40 @@ type ItemViewModel struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Quantity    int32  `json:"quantity"`
+	UnitPrice   Money  `json:"unit_price"`
+	LineTotal   Money  `json:"line_total"`
 }
 
-func (e Item) toViewModel() ItemViewModel {
+func (e Item) ToViewModel() ItemViewModel {
 	return ItemViewModel{
 		ID:          e.ID,
 		Name:        e.Name,
 		Description: e.Description,
 		Quantity:    e.Quantity,
+		UnitPrice:   NewMoney(e.UnitPrice, e.Currency),
+		LineTotal:   NewMoney(e.LineTotal, e.Currency),
 	}
 }
 
 func itemListToViewModel(e Items) []ItemViewModel {
 	items := make([]ItemViewModel, len(e))
 	for i, itemEntity := range e {
-		item := itemEntity.toViewModel()
+		item := itemEntity.ToViewModel()
 		items[i] = item
 	}
 
 	return items
 }
 
-func (vm ItemViewModel) toEntity(orderID int64) Item {
+func (vm ItemViewModel) ToEntity(orderID int64) Item {
 	return Item{
 		ID:          vm.ID,
 		Name:        vm.Name,
 		Description: vm.Description,
 		Quantity:    vm.Quantity,
+		UnitPrice:   vm.UnitPrice.Amount,
+		Currency:    vm.UnitPrice.Currency,
+		LineTotal:   vm.LineTotal.Amount,
 		OrderID:     orderID,
 	}
 }
@@ -57,9 +71,34 @@ func (vm ItemViewModel) toEntity(orderID int64) Item {
 func itemViewModelListToEntity(orderID int64, vm ItemViewModels) []Item {
 	items := make([]Item, len(vm))
 	for i, itemViewModel := range vm {
-		item := itemViewModel.toEntity(orderID)
+		item := itemViewModel.ToEntity(orderID)
 		items[i] = item
 	}
 
 	return items
 }
+
+// ItemPatch holds the fields of an item to change. Nil fields are left as
+// they are.
+type ItemPatch struct {
+	Name        *string
+	Description *string
+	Quantity    *int32
+	UnitPrice   *Money
+}
+
+func (p ItemPatch) Apply(e *Item) {
+	if p.Name != nil {
+		e.Name = *p.Name
+	}
+	if p.Description != nil {
+		e.Description = *p.Description
+	}
+	if p.Quantity != nil {
+		e.Quantity = *p.Quantity
+	}
+	if p.UnitPrice != nil {
+		e.UnitPrice = p.UnitPrice.Amount
+		e.Currency = p.UnitPrice.Currency
+	}
+}
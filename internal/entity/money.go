@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Money is an exact decimal amount in an ISO 4217 currency. Amounts are
+// encoded as JSON strings so that no precision is lost to float parsing.
+type Money struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+func NewMoney(amount decimal.Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
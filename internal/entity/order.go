@@ -2,44 +2,69 @@ package entity
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type Orders []Order
 
 type Order struct {
-	ID           int64     `gorm:"primary_key;column:id;autoIncrement"`
-	CustomerName string    `gorm:"column:customer_name"`
-	OrderedAt    time.Time `gorm:"column:ordered_at"`
-	Items        []Item    `gorm:"foreignKey:OrderID;references:ID;constraint:OnDelete:CASCADE"`
-	UpdatedAt    time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
-	CreatedAt    time.Time `gorm:"column:updated_at;autoCreateTime"`
+	ID           int64           `gorm:"primary_key;column:id;autoIncrement"`
+	CustomerName string          `gorm:"column:customer_name"`
+	Owner        string          `gorm:"column:owner;index"`
+	OrderedAt    time.Time       `gorm:"column:ordered_at"`
+	Status       OrderStatus     `gorm:"column:status;default:pending"`
+	Currency     string          `gorm:"column:currency"`
+	Subtotal     decimal.Decimal `gorm:"column:subtotal;type:numeric(19,4)"`
+	Total        decimal.Decimal `gorm:"column:total;type:numeric(19,4)"`
+	Version      int64           `gorm:"column:version;default:1"`
+	Items        []Item          `gorm:"foreignKey:OrderID;references:ID;constraint:OnDelete:CASCADE"`
+	UpdatedAt    time.Time       `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	CreatedAt    time.Time       `gorm:"column:created_at;autoCreateTime"`
+	DeletedAt    gorm.DeletedAt  `gorm:"column:deleted_at;index"`
 }
 
 type OrderViewModel struct {
 	ID           int64           `json:"id"`
 	CustomerName string          `json:"customer_name"`
+	Owner        string          `json:"owner"`
 	OrderedAt    time.Time       `json:"ordered_at"`
+	Status       OrderStatus     `json:"status"`
 	Items        []ItemViewModel `json:"items"`
+	Subtotal     Money           `json:"subtotal"`
+	Total        Money           `json:"total"`
+	Version      int64           `json:"version"`
+	DeletedAt    *time.Time      `json:"deleted_at,omitempty"`
 }
 
 func (e Order) ToViewModel() OrderViewModel {
 	return OrderViewModel{
 		ID:           e.ID,
 		CustomerName: e.CustomerName,
+		Owner:        e.Owner,
+		OrderedAt:    e.OrderedAt,
+		Status:       e.Status,
 		Items:        itemListToViewModel(e.Items),
+		Subtotal:     NewMoney(e.Subtotal, e.Currency),
+		Total:        NewMoney(e.Total, e.Currency),
+		Version:      e.Version,
+		DeletedAt:    deletedAt(e.DeletedAt),
+	}
+}
+
+func deletedAt(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
 	}
+	return &d.Time
 }
 
 func (e Orders) ToViewModel() []OrderViewModel {
 	orders := make([]OrderViewModel, len(e))
 
 	for i, order := range e {
-		orders[i] = OrderViewModel{
-			ID:           order.ID,
-			CustomerName: order.CustomerName,
-			OrderedAt:    order.OrderedAt,
-			Items:        itemListToViewModel(order.Items),
-		}
+		orders[i] = order.ToViewModel()
 	}
 
 	return orders
@@ -49,7 +74,13 @@ func (vm OrderViewModel) ToEntity() Order {
 	return Order{
 		ID:           vm.ID,
 		CustomerName: vm.CustomerName,
+		Owner:        vm.Owner,
 		OrderedAt:    vm.OrderedAt,
+		Status:       vm.Status,
+		Currency:     vm.Total.Currency,
+		Subtotal:     vm.Subtotal.Amount,
+		Total:        vm.Total.Amount,
+		Version:      vm.Version,
 		Items:        itemViewModelListToEntity(int64(vm.ID), vm.Items),
 	}
 }
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"time"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusConfirmed OrderStatus = "confirmed"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusRefunded  OrderStatus = "refunded"
+)
+
+// orderStatusTransitions lists the statuses each status may move to. An order
+// can be cancelled until it is paid and refunded from then on; cancelled and
+// refunded are final.
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:   {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusShipped, OrderStatusRefunded},
+	OrderStatusShipped:   {OrderStatusDelivered, OrderStatusRefunded},
+	OrderStatusDelivered: {OrderStatusRefunded},
+}
+
+func (s OrderStatus) CanTransitionTo(to OrderStatus) bool {
+	for _, next := range orderStatusTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionRole is the least role that may move an order from s to to.
+// Customers may confirm or cancel their pending orders; paying, shipping,
+// delivering, refunding and cancelling confirmed orders is up to staff.
+func (s OrderStatus) TransitionRole(to OrderStatus) Role {
+	if s == OrderStatusPending {
+		return RoleCustomer
+	}
+	return RoleStaff
+}
+
+type OrderStatusTransition struct {
+	ID         int64       `gorm:"primary_key;column:id;autoIncrement"`
+	OrderID    int64       `gorm:"index;column:order_id"`
+	FromStatus OrderStatus `gorm:"column:from_status"`
+	ToStatus   OrderStatus `gorm:"column:to_status"`
+	CreatedAt  time.Time   `gorm:"column:created_at;autoCreateTime"`
+}
+
+type OrderStatusTransitionViewModel struct {
+	From      OrderStatus `json:"from"`
+	To        OrderStatus `json:"to"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func (e OrderStatusTransition) ToViewModel() OrderStatusTransitionViewModel {
+	return OrderStatusTransitionViewModel{
+		From:      e.FromStatus,
+		To:        e.ToStatus,
+		CreatedAt: e.CreatedAt,
+	}
+}
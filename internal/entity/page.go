@@ -0,0 +1,18 @@
+package entity
+
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// OrderCursor points at the last order of a page. Value holds that order's
+// sort key for the sort the page was requested with.
+type OrderCursor struct {
+	Value interface{}
+	ID    int64
+}
+
+type OrderPage struct {
+	Data       []OrderViewModel `json:"data"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+)
+
+type OrderSortField string
+
+const (
+	OrderSortCreatedAt       OrderSortField = "created_at"
+	OrderSortCustomerName    OrderSortField = "customer_name"
+	OrderSortOrderedAt       OrderSortField = "ordered_at"
+	OrderSortItemDescription OrderSortField = "item_description"
+	OrderSortItemQuantity    OrderSortField = "item_quantity"
+)
+
+func (f OrderSortField) IsValid() bool {
+	switch f {
+	case OrderSortCreatedAt, OrderSortCustomerName, OrderSortOrderedAt,
+		OrderSortItemDescription, OrderSortItemQuantity:
+		return true
+	}
+	return false
+}
+
+// OrderFilter narrows an order listing. Zero values are ignored. Item
+// conditions match orders having at least one item that satisfies them.
+// Deleted orders are left out unless IncludeDeleted is set.
+type OrderFilter struct {
+	Owner              string
+	CustomerName       string
+	CustomerNamePrefix string
+	OrderedFrom        *time.Time
+	OrderedTo          *time.Time
+	ItemDescription    string
+	MinItemQuantity    int32
+	IncludeDeleted     bool
+}
+
+// OrderSort orders a listing by Field, with the order ID as tie-breaker.
+// Item fields sort by the alphabetically first description and the largest
+// quantity of the order's items respectively.
+type OrderSort struct {
+	Field OrderSortField
+	Desc  bool
+}
+
+type OrderQuery struct {
+	Filter OrderFilter
+	Sort   OrderSort
+}
@@ -0,0 +1,29 @@
+package entity
+
+// Role decides what a caller may do with orders. Customers only reach their
+// own orders and can only confirm or cancel them while pending, staff reach
+// every order and move it through its other statuses, and admins may also
+// delete, restore and purge them.
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleStaff    Role = "staff"
+	RoleAdmin    Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleCustomer: 1,
+	RoleStaff:    2,
+	RoleAdmin:    3,
+}
+
+func (r Role) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast reports whether r grants everything other grants.
+func (r Role) AtLeast(other Role) bool {
+	return roleRanks[r] >= roleRanks[other]
+}
@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDead marks a delivery that failed every attempt. It is only
+	// sent again when redelivered by hand.
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// WebhookSubscription is a partner endpoint that receives the events of the
+// listed types. Deliveries are signed with Secret.
+type WebhookSubscription struct {
+	ID         int64       `gorm:"primary_key;column:id;autoIncrement"`
+	URL        string      `gorm:"column:url"`
+	EventTypes []EventType `gorm:"column:event_types;type:jsonb;serializer:json"`
+	Secret     string      `gorm:"column:secret"`
+	CreatedAt  time.Time   `gorm:"column:created_at;autoCreateTime"`
+}
+
+// WebhookSubscriptionViewModel only carries the secret in the response to
+// creating the subscription.
+type WebhookSubscriptionViewModel struct {
+	ID         int64       `json:"id"`
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	Secret     string      `json:"secret,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func (s WebhookSubscription) ToViewModel() WebhookSubscriptionViewModel {
+	return WebhookSubscriptionViewModel{
+		ID:         s.ID,
+		URL:        s.URL,
+		EventTypes: s.EventTypes,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+func (s WebhookSubscriptionViewModel) ToEntity() WebhookSubscription {
+	return WebhookSubscription{
+		ID:         s.ID,
+		URL:        s.URL,
+		EventTypes: s.EventTypes,
+		Secret:     s.Secret,
+	}
+}
+
+// WebhookDelivery is an event on its way to a subscription. Payload is the
+// exact body that is sent, so every attempt carries the same bytes.
+type WebhookDelivery struct {
+	ID             int64                    `gorm:"primary_key;column:id;autoIncrement"`
+	SubscriptionID int64                    `gorm:"uniqueIndex:idx_subscription_event;column:subscription_id"`
+	EventID        int64                    `gorm:"uniqueIndex:idx_subscription_event;column:event_id"`
+	EventType      EventType                `gorm:"column:event_type"`
+	Payload        json.RawMessage          `gorm:"column:payload;type:jsonb"`
+	Status         DeliveryStatus           `gorm:"column:status"`
+	Attempts       int                      `gorm:"column:attempts"`
+	LastError      string                   `gorm:"column:last_error"`
+	NextAttemptAt  time.Time                `gorm:"column:next_attempt_at"`
+	DeliveredAt    *time.Time               `gorm:"column:delivered_at"`
+	CreatedAt      time.Time                `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time                `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	Subscription   WebhookSubscription      `gorm:"foreignKey:SubscriptionID"`
+	Log            []WebhookDeliveryAttempt `gorm:"foreignKey:DeliveryID"`
+}
+
+// WebhookDeliveryAttempt logs one attempt at sending a delivery.
+// ResponseStatus is zero if no response arrived.
+type WebhookDeliveryAttempt struct {
+	ID             int64     `gorm:"primary_key;column:id;autoIncrement"`
+	DeliveryID     int64     `gorm:"index;column:delivery_id"`
+	ResponseStatus int       `gorm:"column:response_status"`
+	Error          string    `gorm:"column:error"`
+	DurationMs     int64     `gorm:"column:duration_ms"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+type WebhookDeliveryViewModel struct {
+	ID             int64                             `json:"id"`
+	SubscriptionID int64                             `json:"subscription_id"`
+	EventID        int64                             `json:"event_id"`
+	EventType      EventType                         `json:"event_type"`
+	Status         DeliveryStatus                    `json:"status"`
+	Attempts       int                               `json:"attempts"`
+	LastError      string                            `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time                        `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time                        `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time                         `json:"created_at"`
+	Log            []WebhookDeliveryAttemptViewModel `json:"log,omitempty"`
+}
+
+type WebhookDeliveryAttemptViewModel struct {
+	ResponseStatus int       `json:"response_status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (d WebhookDelivery) ToViewModel() WebhookDeliveryViewModel {
+	vm := WebhookDeliveryViewModel{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+
+	if d.Status == DeliveryPending {
+		next := d.NextAttemptAt
+		vm.NextAttemptAt = &next
+	}
+
+	for _, attempt := range d.Log {
+		vm.Log = append(vm.Log, WebhookDeliveryAttemptViewModel{
+			ResponseStatus: attempt.ResponseStatus,
+			Error:          attempt.Error,
+			DurationMs:     attempt.DurationMs,
+			CreatedAt:      attempt.CreatedAt,
+		})
+	}
+
+	return vm
+}
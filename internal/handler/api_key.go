@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.IAPIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService service.IAPIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+type apiKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,gt=0,dive,oneof=orders:read orders:write orders:admin"`
+}
+
+type apiKeyByIDRequest struct {
+	ID int64 `uri:"id" binding:"required,gt=0"`
+}
+
+// CreateAPIKey generates a key. The response carries the key itself; it
+// isn't shown again.
+func (h *APIKeyHandler) CreateAPIKey(ctx *gin.Context) {
+	var req apiKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	scopes := make([]entity.Scope, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = entity.Scope(scope)
+	}
+
+	key, err := h.apiKeyService.CreateKey(ctx.Request.Context(), req.Name, scopes)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) GetAPIKeys(ctx *gin.Context) {
+	keys, err := h.apiKeyService.GetKeys(ctx.Request.Context())
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+// RotateAPIKey replaces a key. Like CreateAPIKey, the response is the only
+// place the new key is shown.
+func (h *APIKeyHandler) RotateAPIKey(ctx *gin.Context) {
+	var req apiKeyByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	key, err := h.apiKeyService.RotateKey(ctx.Request.Context(), req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, key)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(ctx *gin.Context) {
+	var req apiKeyByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	err := h.apiKeyService.RevokeKey(ctx.Request.Context(), req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, successResponse())
+}
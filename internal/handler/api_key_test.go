@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/service"
+	mockService "simple-order-go/internal/service/mock"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	key := randomAPIKey()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(service *mockService.MockIAPIKeyService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": key.Name, "scopes": key.Scopes},
+			buildStubs: func(service *mockService.MockIAPIKeyService) {
+				service.EXPECT().CreateKey(gomock.Any(), key.Name, key.Scopes).Times(1).Return(key, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got entity.APIKeyViewModel
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, key.ID, got.ID)
+				require.Equal(t, key.Key, got.Key)
+			},
+		},
+		{
+			name: "UnknownScope",
+			body: gin.H{"name": key.Name, "scopes": []string{"orders:everything"}},
+			buildStubs: func(service *mockService.MockIAPIKeyService) {
+				service.EXPECT().CreateKey(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				got := requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Equal(t, "scopes[0]", got.Errors[0].Field)
+			},
+		},
+		{
+			name: "NoName",
+			body: gin.H{"scopes": key.Scopes},
+			buildStubs: func(service *mockService.MockIAPIKeyService) {
+				service.EXPECT().CreateKey(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+			},
+		},
+		{
+			name: "Forbidden",
+			body: gin.H{"name": key.Name, "scopes": key.Scopes},
+			buildStubs: func(apiKeyService *mockService.MockIAPIKeyService) {
+				apiKeyService.EXPECT().CreateKey(gomock.Any(), key.Name, key.Scopes).Times(1).
+					Return(entity.APIKeyViewModel{}, service.ErrForbidden)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusForbidden, "forbidden")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "POST"}
+			mockRequest(ctx, tc.body, 0)
+
+			handler, service := setUpAPIKeyHandler(t)
+			tc.buildStubs(service)
+
+			handler.CreateAPIKey(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	key := randomAPIKey()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(service *mockService.MockIAPIKeyService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(service *mockService.MockIAPIKeyService) {
+				service.EXPECT().RotateKey(gomock.Any(), key.ID).Times(1).Return(key, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got entity.APIKeyViewModel
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, key.Key, got.Key)
+			},
+		},
+		{
+			name: "Revoked",
+			buildStubs: func(apiKeyService *mockService.MockIAPIKeyService) {
+				apiKeyService.EXPECT().RotateKey(gomock.Any(), key.ID).Times(1).
+					Return(entity.APIKeyViewModel{}, service.ErrAPIKeyRevoked)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusConflict, "api_key_revoked")
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(apiKeyService *mockService.MockIAPIKeyService) {
+				apiKeyService.EXPECT().RotateKey(gomock.Any(), key.ID).Times(1).
+					Return(entity.APIKeyViewModel{}, service.ErrAPIKeyNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "api_key_not_found")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "POST"}
+			mockRequest(ctx, nil, key.ID)
+
+			handler, service := setUpAPIKeyHandler(t)
+			tc.buildStubs(service)
+
+			handler.RotateAPIKey(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	var keyID int64 = 1
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(service *mockService.MockIAPIKeyService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(service *mockService.MockIAPIKeyService) {
+				service.EXPECT().RevokeKey(gomock.Any(), keyID).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(apiKeyService *mockService.MockIAPIKeyService) {
+				apiKeyService.EXPECT().RevokeKey(gomock.Any(), keyID).Times(1).Return(service.ErrAPIKeyNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "api_key_not_found")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "DELETE"}
+			mockRequest(ctx, nil, keyID)
+
+			handler, service := setUpAPIKeyHandler(t)
+			tc.buildStubs(service)
+
+			handler.RevokeAPIKey(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func randomAPIKey() entity.APIKeyViewModel {
+	return entity.APIKeyViewModel{
+		ID:        1,
+		Name:      "nightly export",
+		Prefix:    "sok_Ab3dEfGh",
+		Key:       "sok_Ab3dEfGhIjKlMnOpQrStUvWxYz0123456789-_abcdEF",
+		Scopes:    []entity.Scope{entity.ScopeOrdersRead},
+		CreatedBy: "admin-1",
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func setUpAPIKeyHandler(t *testing.T) (*APIKeyHandler, *mockService.MockIAPIKeyService) {
+	ctrl := gomock.NewController(t)
+
+	apiKeyService := mockService.NewMockIAPIKeyService(ctrl)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService)
+
+	return apiKeyHandler, apiKeyService
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+var (
+	errIfMatchRequired  = errors.New("If-Match header is required")
+	errIfMatchMalformed = errors.New("If-Match header must be * or a list of quoted ETags")
+)
+
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ifMatchVersion reads the order version a client expects from the If-Match
+// header. A single ETag is returned as it is. "*" and lists of ETags are
+// resolved against the order's current version, which currentVersion looks
+// up: "*" matches any existing order, and a list matches if it holds the
+// current version. Tags that can never match, such as weak ETags or ones we
+// didn't issue, and lists that don't match are reported as version 0, so the
+// change fails its precondition.
+func ifMatchVersion(ctx *gin.Context, currentVersion func() (int64, error)) (int64, error) {
+	value := strings.TrimSpace(ctx.GetHeader(ifMatchHeader))
+	if value == "" {
+		return 0, errIfMatchRequired
+	}
+
+	if value == "*" {
+		return currentVersion()
+	}
+
+	versions, err := parseETags(value)
+	if err != nil {
+		return 0, err
+	}
+	if len(versions) == 1 {
+		return versions[0], nil
+	}
+
+	current, err := currentVersion()
+	if err != nil {
+		return 0, err
+	}
+	for _, version := range versions {
+		if version == current {
+			return current, nil
+		}
+	}
+	return 0, nil
+}
+
+// parseETags parses a comma separated list of entity tags, as defined in
+// RFC 9110 section 8.8.3, into the order versions they stand for. Tags that
+// aren't one of our strong ETags are reported as version 0.
+func parseETags(value string) ([]int64, error) {
+	var versions []int64
+	for {
+		value = strings.TrimLeft(value, " \t,")
+		if value == "" {
+			break
+		}
+
+		weak := strings.HasPrefix(value, "W/")
+		value = strings.TrimPrefix(value, "W/")
+		if !strings.HasPrefix(value, `"`) {
+			return nil, errIfMatchMalformed
+		}
+
+		end := strings.IndexByte(value[1:], '"')
+		if end < 0 {
+			return nil, errIfMatchMalformed
+		}
+		tag := value[1 : end+1]
+		value = strings.TrimLeft(value[end+2:], " \t")
+		if value != "" && value[0] != ',' {
+			return nil, errIfMatchMalformed
+		}
+
+		version, err := strconv.ParseInt(tag, 10, 64)
+		if weak || err != nil || version <= 0 {
+			version = 0
+		}
+		versions = append(versions, version)
+	}
+
+	if len(versions) == 0 {
+		return nil, errIfMatchMalformed
+	}
+	return versions, nil
+}
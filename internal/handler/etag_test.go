@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	var current int64 = 7
+	errLookup := errors.New("lookup failed")
+
+	testCases := []struct {
+		name    string
+		ifMatch string
+		lookup  error
+		version int64
+		err     error
+		lookups int
+	}{
+		{name: "Single", ifMatch: `"3"`, version: 3},
+		{name: "Missing", ifMatch: "", err: errIfMatchRequired},
+		{name: "Any", ifMatch: "*", version: current, lookups: 1},
+		{name: "AnyLookupFails", ifMatch: "*", lookup: errLookup, err: errLookup, lookups: 1},
+		{name: "ListMatches", ifMatch: `"3", "7"`, version: current, lookups: 1},
+		{name: "ListWithoutSpaces", ifMatch: `"7","3"`, version: current, lookups: 1},
+		{name: "ListMismatch", ifMatch: `"3", "4"`, version: 0, lookups: 1},
+		{name: "ListWithWeakTag", ifMatch: `W/"7", "3"`, version: 0, lookups: 1},
+		{name: "ListWithForeignTag", ifMatch: `"abc", "7"`, version: current, lookups: 1},
+		{name: "EmptyListElements", ifMatch: `, "3",`, version: 3},
+		{name: "Weak", ifMatch: `W/"3"`, version: 0},
+		{name: "Foreign", ifMatch: `"abc"`, version: 0},
+		{name: "Zero", ifMatch: `"0"`, version: 0},
+		{name: "Unquoted", ifMatch: "3", err: errIfMatchMalformed},
+		{name: "Unterminated", ifMatch: `"3`, err: errIfMatchMalformed},
+		{name: "MissingComma", ifMatch: `"3" "4"`, err: errIfMatchMalformed},
+		{name: "AnyInList", ifMatch: `*, "3"`, err: errIfMatchMalformed},
+		{name: "OnlyCommas", ifMatch: ",,", err: errIfMatchMalformed},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = &http.Request{Header: make(http.Header)}
+			if tc.ifMatch != "" {
+				ctx.Request.Header.Set(ifMatchHeader, tc.ifMatch)
+			}
+
+			lookups := 0
+			version, err := ifMatchVersion(ctx, func() (int64, error) {
+				lookups++
+				return current, tc.lookup
+			})
+			require.Equal(t, tc.lookups, lookups)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.version, version)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"simple-order-go/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// Live reports that the process is up. It checks no dependencies, so that a
+// database outage doesn't get the service restarted.
+func (h *HealthHandler) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Ready reports whether the service can serve requests, with the outcome
+// and latency of every check. It answers 503 if any check failed.
+func (h *HealthHandler) Ready(ctx *gin.Context) {
+	report, ok := h.checker.Ready(ctx.Request.Context())
+	if !ok {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/health"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReady(t *testing.T) {
+	passing := health.Check{Name: "database", Run: func(ctx context.Context) error { return nil }}
+	failing := health.Check{Name: "migrations", Run: func(ctx context.Context) error { return errors.New("schema is at version 11, want 12") }}
+
+	testCases := []struct {
+		name          string
+		checker       func() *health.Checker
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			checker: func() *health.Checker { return health.NewChecker(passing) },
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got health.Report
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, health.StatusOK, got.Status)
+				require.Equal(t, health.StatusOK, got.Checks["database"].Status)
+			},
+		},
+		{
+			name:    "CheckFailed",
+			checker: func() *health.Checker { return health.NewChecker(passing, failing) },
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+				var got health.Report
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, health.StatusFail, got.Status)
+				require.Equal(t, health.StatusOK, got.Checks["database"].Status)
+				require.Equal(t, "schema is at version 11, want 12", got.Checks["migrations"].Error)
+			},
+		},
+		{
+			name: "ShuttingDown",
+			checker: func() *health.Checker {
+				checker := health.NewChecker(passing)
+				checker.ShuttingDown()
+				return checker
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Contains(t, recorder.Body.String(), health.ErrShuttingDown.Error())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+			NewHealthHandler(tc.checker()).Ready(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestLive(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+
+	checker := health.NewChecker()
+	checker.ShuttingDown()
+
+	NewHealthHandler(checker).Live(ctx)
+	require.Equal(t, http.StatusOK, w.Code)
+}
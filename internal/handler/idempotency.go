@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/reqctx"
+	"simple-order-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+type IdempotencyHandler struct {
+	idempotencyService service.IIdempotencyService
+}
+
+func NewIdempotencyHandler(idempotencyService service.IIdempotencyService) *IdempotencyHandler {
+	return &IdempotencyHandler{idempotencyService: idempotencyService}
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotent makes the handlers after it safe to retry. Requests carrying an
+// Idempotency-Key header are processed once; retries with the same key and
+// body get the stored response, and reusing a key for a different body is
+// rejected. Keys are scoped to the authenticated caller, so one caller can't
+// replay or block another's requests. Only successful responses are stored.
+func (h *IdempotencyHandler) Idempotent(ctx *gin.Context) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		ctx.Next()
+		return
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		errorResponse(ctx, apperror.Validation("invalid_idempotency_key", "request validation failed", apperror.FieldError{
+			Field:   idempotencyKeyHeader,
+			Code:    "max",
+			Message: fmt.Sprintf("must be at most %d characters", maxIdempotencyKeyLength),
+		}))
+		return
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	owner := reqctx.Actor(ctx.Request.Context())
+	record, err := h.idempotencyService.Begin(ctx.Request.Context(), owner, key, fingerprint(ctx.Request, body))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	if record.Completed() {
+		ctx.Header(idempotentReplayedHeader, "true")
+		ctx.Data(record.StatusCode, gin.MIMEJSON+"; charset=utf-8", record.ResponseBody)
+		ctx.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+	ctx.Writer = recorder
+
+	// Record the outcome even if the client has gone away in the meantime, so
+	// the key isn't left in progress. A handler that panics releases the key
+	// before the panic goes on to the recovery middleware.
+	c := context.WithoutCancel(ctx.Request.Context())
+	lockToken := record.LockToken
+	defer func() {
+		if p := recover(); p != nil {
+			if err := h.idempotencyService.Release(c, owner, key, lockToken); err != nil {
+				_ = ctx.Error(err)
+			}
+			panic(p)
+		}
+	}()
+
+	ctx.Next()
+
+	status := recorder.Status()
+	if status >= http.StatusOK && status < http.StatusMultipleChoices {
+		err = h.idempotencyService.Complete(c, owner, key, lockToken, status, recorder.body.Bytes())
+	} else {
+		err = h.idempotencyService.Release(c, owner, key, lockToken)
+	}
+	if err != nil {
+		_ = ctx.Error(err)
+	}
+}
+
+func fingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(req.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
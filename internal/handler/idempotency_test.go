@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/common"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/internal/reqctx"
+	"simple-order-go/internal/service"
+	mockService "simple-order-go/internal/service/mock"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotent(t *testing.T) {
+	key := common.RandomString(16)
+	body := []byte(`{"customerName":"` + common.RandomName() + `"}`)
+	stored := []byte(`{"id":1}`)
+	claimed := &entity.IdempotencyKey{Key: key, LockToken: common.RandomString(32)}
+
+	testCases := []struct {
+		name          string
+		key           string
+		handlerStatus int
+		handlerPanics bool
+		buildStubs    func(service *mockService.MockIIdempotencyService)
+		checkResponse func(recorder *httptest.ResponseRecorder, handlerCalled bool)
+	}{
+		{
+			name:          "NoKey",
+			handlerStatus: http.StatusOK,
+			buildStubs: func(service *mockService.MockIIdempotencyService) {
+				service.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, handlerCalled bool) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.True(t, handlerCalled)
+			},
+		},
+		{
+			name:          "FirstRequest",
+			key:           key,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(service *mockService.MockIIdempotencyService) {
+				service.EXPECT().Begin(gomock.Any(), gomock.Any(), key, gomock.Any()).Times(1).Return(claimed, nil)
+				service.EXPECT().Complete(gomock.Any(), gomock.Any(), key, claimed.LockToken, http.StatusOK, []byte(`{"result":"Success"}`)).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, handlerCalled bool) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.True(t, handlerCalled)
+			},
+		},
+		{
+			name:          "FailedRequest",
+			key:           key,
+			handlerStatus: http.StatusInternalServerError,
+			buildStubs: func(service *mockService.MockIIdempotencyService) {
+				service.EXPECT().Begin(gomock.Any(), gomock.Any(), key, gomock.Any()).Times(1).Return(claimed, nil)
+				service.EXPECT().Release(gomock.Any(), gomock.Any(), key, claimed.LockToken).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, handlerCalled bool) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.True(t, handlerCalled)
+			},
+		},
+		{
+			name:          "HandlerPanics",
+			key:           key,
+			handlerPanics: true,
+			buildStubs: func(service *mockService.MockIIdempotencyService) {
+				service.EXPECT().Begin(gomock.Any(), gomock.Any(), key, gomock.Any()).Times(1).Return(claimed, nil)
+				service.EXPECT().Complete(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().Release(gomock.Any(), gomock.Any(), key, claimed.LockToken).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, handlerCalled bool) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.True(t, handlerCalled)
+			},
+		},
+		{
+			name:          "LockLost",
+			key:           key,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(service *mockService.MockIIdempotencyService) {
+				service.EXPECT().Begin(gomock.Any(), gomock.Any(), key, gomock.Any()).Times(1).Return(claimed, nil)
+				service.EXPECT().Complete(gomock.Any(), gomock.Any(), key, claimed.LockToken, http.StatusOK, gomock.Any()).Times(1).Return(repository.ErrIdempotencyLockLost)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, handlerCalled bool) {
+				// The response was already sent; losing the key is only logged.
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.True(t, handlerCalled)
+			},
+		},
+		{
+			name:          "Replay",
+			key:           key,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(service *mockService.MockIIdempotencyService) {
+				record := &entity.IdempotencyKey{Key: key, StatusCode: http.StatusOK, ResponseBody: stored}
+				service.EXPECT().Begin(gomock.Any(), gomock.Any(), key, gomock.Any()).Times(1).Return(record, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, handlerCalled bool) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.False(t, handlerCalled)
+				require.Equal(t, stored, recorder.Body.Bytes())
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+			},
+		},
+		{
+			name:          "KeyReused",
+			key:           key,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(idempotencyService *mockService.MockIIdempotencyService) {
+				idempotencyService.EXPECT().Begin(gomock.Any(), gomock.Any(), key, gomock.Any()).Times(1).Return(nil, service.ErrIdempotencyKeyReused)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, handlerCalled bool) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.False(t, handlerCalled)
+			},
+		},
+		{
+			name:          "InProgress",
+			key:           key,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(idempotencyService *mockService.MockIIdempotencyService) {
+				idempotencyService.EXPECT().Begin(gomock.Any(), gomock.Any(), key, gomock.Any()).Times(1).Return(nil, service.ErrIdempotencyKeyInProgress)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, handlerCalled bool) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				require.False(t, handlerCalled)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			idempotencyService := mockService.NewMockIIdempotencyService(ctrl)
+			tc.buildStubs(idempotencyService)
+
+			handlerCalled := false
+			router := gin.New()
+			router.Use(gin.RecoveryWithWriter(io.Discard))
+			router.POST("/orders", NewIdempotencyHandler(idempotencyService).Idempotent, func(ctx *gin.Context) {
+				handlerCalled = true
+				if tc.handlerPanics {
+					panic("handler failed")
+				}
+				ctx.JSON(tc.handlerStatus, successResponse())
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+			if tc.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tc.key)
+			}
+
+			router.ServeHTTP(w, req)
+			tc.checkResponse(w, handlerCalled)
+		})
+	}
+}
+
+func TestIdempotentScopedToCaller(t *testing.T) {
+	key := common.RandomString(16)
+	body := []byte(`{"customerName":"` + common.RandomName() + `"}`)
+	stored := []byte(`{"id":1,"owner":"customer-1"}`)
+
+	ctrl := gomock.NewController(t)
+	idempotencyService := mockService.NewMockIIdempotencyService(ctrl)
+
+	// customer-1 has already completed a request with the key; customer-2
+	// using the same key must get a request of its own, not that response.
+	record := &entity.IdempotencyKey{Owner: "customer-1", Key: key, StatusCode: http.StatusCreated, ResponseBody: stored}
+	idempotencyService.EXPECT().Begin(gomock.Any(), "customer-1", key, gomock.Any()).Times(1).Return(record, nil)
+	claimed := &entity.IdempotencyKey{Owner: "customer-2", Key: key, LockToken: common.RandomString(32)}
+	idempotencyService.EXPECT().Begin(gomock.Any(), "customer-2", key, gomock.Any()).Times(1).Return(claimed, nil)
+	idempotencyService.EXPECT().Complete(gomock.Any(), "customer-2", key, claimed.LockToken, http.StatusCreated, gomock.Any()).Times(1).Return(nil)
+
+	handlerCalls := 0
+	router := gin.New()
+	router.POST("/orders", func(ctx *gin.Context) {
+		actor := ctx.GetHeader("X-Test-Actor")
+		ctx.Request = ctx.Request.WithContext(reqctx.WithActor(ctx.Request.Context(), actor))
+	}, NewIdempotencyHandler(idempotencyService).Idempotent, func(ctx *gin.Context) {
+		handlerCalls++
+		ctx.JSON(http.StatusCreated, successResponse())
+	})
+
+	send := func(actor string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+		req.Header.Set(idempotencyKeyHeader, key)
+		req.Header.Set("X-Test-Actor", actor)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("customer-1")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, stored, w.Body.Bytes())
+	require.Equal(t, 0, handlerCalls)
+
+	w = send("customer-2")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotEqual(t, stored, w.Body.Bytes())
+	require.Empty(t, w.Header().Get(idempotentReplayedHeader))
+	require.Equal(t, 1, handlerCalls)
+}
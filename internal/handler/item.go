@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type ItemHandler struct {
+	itemService service.IItemService
+}
+
+func NewItemHandler(itemService service.IItemService) *ItemHandler {
+	return &ItemHandler{itemService: itemService}
+}
+
+type itemByIDRequest struct {
+	OrderID int64 `uri:"id" binding:"required,gt=0"`
+	ItemID  int64 `uri:"itemId" binding:"required,gt=0"`
+}
+
+type patchItemRequest struct {
+	Name      *string          `json:"name" binding:"omitempty,min=1"`
+	Desc      *string          `json:"description" binding:"omitempty,min=1"`
+	Quantity  *int32           `json:"quantity" binding:"omitempty,gt=0"`
+	UnitPrice *decimal.Decimal `json:"unitPrice" binding:"required_with=Currency"`
+	Currency  *string          `json:"currency" binding:"required_with=UnitPrice,omitempty,iso4217"`
+}
+
+func (h *ItemHandler) GetItems(ctx *gin.Context) {
+	var req orderByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	items, err := h.itemService.GetItems(ctx.Request.Context(), req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+func (h *ItemHandler) GetItem(ctx *gin.Context) {
+	var req itemByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	item, err := h.itemService.GetItem(ctx.Request.Context(), req.OrderID, req.ItemID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+func (h *ItemHandler) CreateItem(ctx *gin.Context) {
+	var idReq orderByIDRequest
+	if err := ctx.ShouldBindUri(&idReq); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	var req itemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	arg := entity.ItemViewModel{
+		Name:        req.Name,
+		Description: req.Desc,
+		Quantity:    req.Quantity,
+		UnitPrice:   entity.NewMoney(*req.UnitPrice, req.Currency),
+	}
+
+	item, err := h.itemService.CreateItem(ctx.Request.Context(), idReq.ID, arg)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+func (h *ItemHandler) UpdateItem(ctx *gin.Context) {
+	var idReq itemByIDRequest
+	if err := ctx.ShouldBindUri(&idReq); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	var req patchItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	patch := entity.ItemPatch{
+		Name:        req.Name,
+		Description: req.Desc,
+		Quantity:    req.Quantity,
+	}
+	if req.UnitPrice != nil {
+		price := entity.NewMoney(*req.UnitPrice, *req.Currency)
+		patch.UnitPrice = &price
+	}
+
+	item, err := h.itemService.UpdateItem(ctx.Request.Context(), idReq.OrderID, idReq.ItemID, patch)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+func (h *ItemHandler) DeleteItem(ctx *gin.Context) {
+	var req itemByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	err := h.itemService.DeleteItem(ctx.Request.Context(), req.OrderID, req.ItemID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, successResponse())
+}
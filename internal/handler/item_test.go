@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/service"
+	mockService "simple-order-go/internal/service/mock"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateItem(t *testing.T) {
+	var orderID int64 = 1
+	item := randomItem()
+
+	testCases := []struct {
+		name          string
+		body          itemRequest
+		buildStubs    func(service *mockService.MockIItemService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: itemRequest{
+				Name:      item.Name,
+				Desc:      item.Description,
+				Quantity:  item.Quantity,
+				UnitPrice: &item.UnitPrice.Amount,
+				Currency:  item.UnitPrice.Currency,
+			},
+			buildStubs: func(service *mockService.MockIItemService) {
+				service.EXPECT().CreateItem(gomock.Any(), orderID, item).Times(1).Return(item, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchItem(t, recorder, item)
+			},
+		},
+		{
+			name: "MissingRequiredData",
+			body: itemRequest{Name: item.Name},
+			buildStubs: func(service *mockService.MockIItemService) {
+				service.EXPECT().CreateItem(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingUnitPrice",
+			body: itemRequest{
+				Name:     item.Name,
+				Desc:     item.Description,
+				Quantity: item.Quantity,
+				Currency: item.UnitPrice.Currency,
+			},
+			buildStubs: func(service *mockService.MockIItemService) {
+				service.EXPECT().CreateItem(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Contains(t, recorder.Body.String(), `"field":"unitPrice"`)
+			},
+		},
+		{
+			name: "OrderNotFound",
+			body: itemRequest{
+				Name:      item.Name,
+				Desc:      item.Description,
+				Quantity:  item.Quantity,
+				UnitPrice: &item.UnitPrice.Amount,
+				Currency:  item.UnitPrice.Currency,
+			},
+			buildStubs: func(itemService *mockService.MockIItemService) {
+				itemService.EXPECT().CreateItem(gomock.Any(), orderID, item).Times(1).Return(entity.ItemViewModel{}, service.ErrOrderNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "MixedCurrency",
+			body: itemRequest{
+				Name:      item.Name,
+				Desc:      item.Description,
+				Quantity:  item.Quantity,
+				UnitPrice: &item.UnitPrice.Amount,
+				Currency:  "EUR",
+			},
+			buildStubs: func(itemService *mockService.MockIItemService) {
+				itemService.EXPECT().CreateItem(gomock.Any(), orderID, gomock.Any()).Times(1).Return(entity.ItemViewModel{}, service.ErrInvalidOrder)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "POST"}
+			mockRequest(ctx, tc.body, orderID)
+
+			handler, service := setUpItemHandler(t)
+			tc.buildStubs(service)
+
+			handler.CreateItem(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestUpdateItem(t *testing.T) {
+	var orderID, itemID int64 = 1, 2
+	item := randomItem()
+	item.ID = itemID
+	quantity := int32(7)
+	price := item.UnitPrice.Amount
+
+	testCases := []struct {
+		name          string
+		body          patchItemRequest
+		buildStubs    func(service *mockService.MockIItemService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: patchItemRequest{Quantity: &quantity},
+			buildStubs: func(service *mockService.MockIItemService) {
+				service.EXPECT().UpdateItem(gomock.Any(), orderID, itemID, entity.ItemPatch{Quantity: &quantity}).Times(1).Return(item, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchItem(t, recorder, item)
+			},
+		},
+		{
+			name: "PriceWithoutCurrency",
+			body: patchItemRequest{UnitPrice: &price},
+			buildStubs: func(service *mockService.MockIItemService) {
+				service.EXPECT().UpdateItem(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyWithoutPrice",
+			body: patchItemRequest{Currency: &item.UnitPrice.Currency},
+			buildStubs: func(service *mockService.MockIItemService) {
+				service.EXPECT().UpdateItem(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Contains(t, recorder.Body.String(), `"field":"unitPrice"`)
+			},
+		},
+		{
+			name: "ItemNotFound",
+			body: patchItemRequest{Quantity: &quantity},
+			buildStubs: func(itemService *mockService.MockIItemService) {
+				itemService.EXPECT().UpdateItem(gomock.Any(), orderID, itemID, gomock.Any()).Times(1).Return(entity.ItemViewModel{}, service.ErrItemNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "ConcurrentUpdate",
+			body: patchItemRequest{Quantity: &quantity},
+			buildStubs: func(itemService *mockService.MockIItemService) {
+				itemService.EXPECT().UpdateItem(gomock.Any(), orderID, itemID, gomock.Any()).Times(1).Return(entity.ItemViewModel{}, service.ErrConcurrentUpdate)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "PATCH"}
+			mockRequest(ctx, tc.body, orderID)
+			ctx.Params = append(ctx.Params, gin.Param{Key: "itemId", Value: strconv.FormatInt(itemID, 10)})
+
+			handler, service := setUpItemHandler(t)
+			tc.buildStubs(service)
+
+			handler.UpdateItem(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestDeleteItem(t *testing.T) {
+	var orderID, itemID int64 = 1, 2
+
+	testCases := []struct {
+		name          string
+		itemID        int64
+		buildStubs    func(service *mockService.MockIItemService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			itemID: itemID,
+			buildStubs: func(service *mockService.MockIItemService) {
+				service.EXPECT().DeleteItem(gomock.Any(), orderID, itemID).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ItemNotFound",
+			itemID: itemID,
+			buildStubs: func(itemService *mockService.MockIItemService) {
+				itemService.EXPECT().DeleteItem(gomock.Any(), orderID, itemID).Times(1).Return(service.ErrItemNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NoItemParam",
+			buildStubs: func(service *mockService.MockIItemService) {
+				service.EXPECT().DeleteItem(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "DELETE"}
+			mockRequest(ctx, nil, orderID)
+			if tc.itemID != 0 {
+				ctx.Params = append(ctx.Params, gin.Param{Key: "itemId", Value: strconv.FormatInt(tc.itemID, 10)})
+			}
+
+			handler, service := setUpItemHandler(t)
+			tc.buildStubs(service)
+
+			handler.DeleteItem(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func requireBodyMatchItem(t *testing.T, recorder *httptest.ResponseRecorder, item entity.ItemViewModel) {
+	var gotItem entity.ItemViewModel
+	err := json.Unmarshal(recorder.Body.Bytes(), &gotItem)
+
+	require.NoError(t, err)
+	require.Equal(t, item.Name, gotItem.Name)
+	require.Equal(t, item.Description, gotItem.Description)
+	require.Equal(t, item.Quantity, gotItem.Quantity)
+	require.True(t, item.UnitPrice.Amount.Equal(gotItem.UnitPrice.Amount))
+}
+
+func setUpItemHandler(t *testing.T) (*ItemHandler, *mockService.MockIItemService) {
+	ctrl := gomock.NewController(t)
+
+	itemService := mockService.NewMockIItemService(ctrl)
+	itemHandler := NewItemHandler(itemService)
+
+	return itemHandler, itemService
+}
@@ -1,14 +1,15 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"simple-order-go/common"
+	"simple-order-go/internal/apperror"
 	"simple-order-go/internal/entity"
 	"simple-order-go/internal/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx"
+	"github.com/shopspring/decimal"
 )
 
 type OrderHandler struct {
@@ -32,21 +33,24 @@ type requiredOrderRequest struct {
 }
 
 type itemRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Desc     string `json:"description" binding:"required"`
-	Quantity int32  `json:"quantity" binding:"required,gt=0"`
+	ID        int64            `json:"id" binding:"omitempty,gt=0"`
+	Name      string           `json:"name" binding:"required"`
+	Desc      string           `json:"description" binding:"required"`
+	Quantity  int32            `json:"quantity" binding:"required,gt=0"`
+	UnitPrice *decimal.Decimal `json:"unitPrice" binding:"required"`
+	Currency  string           `json:"currency" binding:"required,iso4217"`
 }
 
 func (h *OrderHandler) CreateOrder(ctx *gin.Context) {
 	var req requiredOrderRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		bindingErrorResponse(ctx, err)
 		return
 	}
 
-	t, err := common.ParseStringToTime(req.OrderedAt)
+	t, err := parseTimeField("orderedAt", req.OrderedAt)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
 
@@ -56,6 +60,7 @@ func (h *OrderHandler) CreateOrder(ctx *gin.Context) {
 			Name:        item.Name,
 			Description: item.Desc,
 			Quantity:    item.Quantity,
+			UnitPrice:   entity.NewMoney(*item.UnitPrice, item.Currency),
 		}
 	}
 
@@ -65,9 +70,9 @@ func (h *OrderHandler) CreateOrder(ctx *gin.Context) {
 		Items:        items,
 	}
 
-	order, err := h.orderService.CreateOrder(arg)
+	order, err := h.orderService.CreateOrder(ctx.Request.Context(), arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
 
@@ -81,59 +86,123 @@ type orderByIDRequest struct {
 func (h *OrderHandler) GetOrderByID(ctx *gin.Context) {
 	var req orderByIDRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		bindingErrorResponse(ctx, err)
 		return
 	}
 
-	order, err := h.orderService.GetOrder(req.ID)
+	order, err := h.orderService.GetOrder(ctx.Request.Context(), req.ID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
 
+	ctx.Header(etagHeader, formatETag(order.Version))
 	ctx.JSON(http.StatusOK, order)
 }
 
+type listOrdersRequest struct {
+	Limit              int    `form:"limit" binding:"omitempty,gt=0,lte=100"`
+	Cursor             string `form:"cursor"`
+	CustomerName       string `form:"customer_name"`
+	CustomerNamePrefix string `form:"customer_name_prefix"`
+	OrderedFrom        string `form:"ordered_from"`
+	OrderedTo          string `form:"ordered_to"`
+	ItemDescription    string `form:"item_description"`
+	MinQuantity        int32  `form:"min_quantity" binding:"omitempty,gt=0"`
+	Sort               string `form:"sort" binding:"omitempty,oneof=created_at customer_name ordered_at item_description item_quantity"`
+	Order              string `form:"order" binding:"omitempty,oneof=asc desc"`
+	IncludeDeleted     bool   `form:"include_deleted"`
+}
+
+func (req listOrdersRequest) toQuery() (entity.OrderQuery, error) {
+	query := entity.OrderQuery{
+		Filter: entity.OrderFilter{
+			CustomerName:       req.CustomerName,
+			CustomerNamePrefix: req.CustomerNamePrefix,
+			ItemDescription:    req.ItemDescription,
+			MinItemQuantity:    req.MinQuantity,
+			IncludeDeleted:     req.IncludeDeleted,
+		},
+		Sort: entity.OrderSort{
+			Field: entity.OrderSortField(req.Sort),
+			Desc:  req.Order == "desc",
+		},
+	}
+
+	if req.OrderedFrom != "" {
+		t, err := parseTimeField("ordered_from", req.OrderedFrom)
+		if err != nil {
+			return query, err
+		}
+		query.Filter.OrderedFrom = &t
+	}
+
+	if req.OrderedTo != "" {
+		t, err := parseTimeField("ordered_to", req.OrderedTo)
+		if err != nil {
+			return query, err
+		}
+		query.Filter.OrderedTo = &t
+	}
+
+	return query, nil
+}
+
 func (h *OrderHandler) GetAllOrders(ctx *gin.Context) {
-	orders, err := h.orderService.GetAllOrders()
+	var req listOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	query, err := req.toQuery()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, orders)
+	page, err := h.orderService.GetOrdersPage(ctx.Request.Context(), query, entity.PageRequest{Limit: req.Limit, Cursor: req.Cursor})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
 }
 
 func (h *OrderHandler) UpdateOrder(ctx *gin.Context) {
 	var idReq orderByIDRequest
 	if err := ctx.ShouldBindUri(&idReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	version, err := ifMatchVersion(ctx, h.currentVersion(ctx, idReq.ID))
+	if err != nil {
+		ifMatchErrorResponse(ctx, err)
 		return
 	}
 
 	var req orderRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		bindingErrorResponse(ctx, err)
 		return
 	}
 
-	t, err := common.ParseStringToTime(req.OrderedAt)
+	t, err := parseTimeField("orderedAt", req.OrderedAt)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
 
 	items := make(entity.ItemViewModels, len(req.Items))
 	for i, item := range req.Items {
 		items[i] = entity.ItemViewModel{
+			ID:          item.ID,
 			Name:        item.Name,
 			Description: item.Desc,
 			Quantity:    item.Quantity,
+			UnitPrice:   entity.NewMoney(*item.UnitPrice, item.Currency),
 		}
 	}
 
@@ -142,42 +211,171 @@ func (h *OrderHandler) UpdateOrder(ctx *gin.Context) {
 		CustomerName: req.CustomerName,
 		OrderedAt:    t,
 		Items:        items,
+		Version:      version,
 	}
 
-	err = h.orderService.UpdateOrder(arg)
+	err = h.orderService.UpdateOrder(ctx.Request.Context(), arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
 
+	ctx.Header(etagHeader, formatETag(version+1))
 	ctx.JSON(http.StatusOK, successResponse())
 }
 
 func (h *OrderHandler) DeleteOrder(ctx *gin.Context) {
 	var req orderByIDRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		bindingErrorResponse(ctx, err)
 		return
 	}
 
-	err := h.orderService.DeleteOrder(req.ID)
+	version, err := ifMatchVersion(ctx, h.currentVersion(ctx, req.ID))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
+		ifMatchErrorResponse(ctx, err)
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	err = h.orderService.DeleteOrder(ctx.Request.Context(), req.ID, version)
+	if err != nil {
+		errorResponse(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, successResponse())
 }
 
+func (h *OrderHandler) RestoreOrder(ctx *gin.Context) {
+	var req orderByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	order, err := h.orderService.RestoreOrder(ctx.Request.Context(), req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header(etagHeader, formatETag(order.Version))
+	ctx.JSON(http.StatusOK, order)
+}
+
+type purgeOrdersRequest struct {
+	OlderThan string `form:"older_than" binding:"required"`
+}
+
+// PurgeOrders permanently removes orders deleted longer ago than the
+// older_than duration, e.g. 720h.
+func (h *OrderHandler) PurgeOrders(ctx *gin.Context) {
+	var req purgeOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	retention, err := time.ParseDuration(req.OlderThan)
+	if err != nil {
+		errorResponse(ctx, apperror.Validation("validation_failed", "request validation failed", apperror.FieldError{
+			Field:   "older_than",
+			Code:    "duration",
+			Message: "must be a duration like 720h",
+		}))
+		return
+	}
+
+	purged, err := h.orderService.PurgeOrders(ctx.Request.Context(), retention)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"purged": purged})
+}
+
+type transitionRequest struct {
+	Status string `json:"status" binding:"required,oneof=pending confirmed paid shipped delivered cancelled refunded"`
+}
+
+func (h *OrderHandler) TransitionOrder(ctx *gin.Context) {
+	var idReq orderByIDRequest
+	if err := ctx.ShouldBindUri(&idReq); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	var req transitionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	order, err := h.orderService.TransitionOrder(ctx.Request.Context(), idReq.ID, entity.OrderStatus(req.Status))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) GetOrderTransitions(ctx *gin.Context) {
+	var req orderByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	transitions, err := h.orderService.GetOrderTransitions(ctx.Request.Context(), req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transitions)
+}
+
+// parseTimeField parses a timestamp from the request, reporting a failure
+// as a validation error on the named field.
+func parseTimeField(field string, value string) (time.Time, error) {
+	t, err := common.ParseStringToTime(value)
+	if err != nil {
+		return t, apperror.Validation("validation_failed", "request validation failed", apperror.FieldError{
+			Field:   field,
+			Code:    "datetime",
+			Message: "must be a timestamp like 2006-01-02T15:04:05+07:00",
+		})
+	}
+	return t, nil
+}
+
+func (h *OrderHandler) GetOrderHistory(ctx *gin.Context) {
+	var req orderByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	history, err := h.orderService.GetOrderHistory(ctx.Request.Context(), req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
 func successResponse() gin.H {
 	return gin.H{"result": "Success"}
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// currentVersion returns a function that looks up the stored version of an
+// order, for resolving If-Match headers that don't name a single version.
+func (h *OrderHandler) currentVersion(ctx *gin.Context, orderID int64) func() (int64, error) {
+	return func() (int64, error) {
+		order, err := h.orderService.GetOrder(ctx.Request.Context(), orderID)
+		return order.Version, err
+	}
 }
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/service"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+var errReadOnlyField = apperror.Validation("read_only_field", "id, owner, status and version can't be changed by a patch")
+
+// orderDocument is the OrderViewModel shape that patches are applied to,
+// with the validation PUT applies to its request body. Totals are ignored
+// since they're recomputed from the items.
+type orderDocument struct {
+	ID           int64              `json:"id"`
+	CustomerName string             `json:"customer_name" binding:"required"`
+	Owner        string             `json:"owner"`
+	OrderedAt    time.Time          `json:"ordered_at" binding:"required"`
+	Status       entity.OrderStatus `json:"status"`
+	Items        []itemDocument     `json:"items" binding:"dive"`
+	Version      int64              `json:"version"`
+}
+
+type itemDocument struct {
+	ID          int64         `json:"id"`
+	Name        string        `json:"name" binding:"required"`
+	Description string        `json:"description" binding:"required"`
+	Quantity    int32         `json:"quantity" binding:"required,gt=0"`
+	UnitPrice   moneyDocument `json:"unit_price"`
+}
+
+type moneyDocument struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency" binding:"required,iso4217"`
+}
+
+func (d orderDocument) toViewModel() entity.OrderViewModel {
+	items := make(entity.ItemViewModels, len(d.Items))
+	for i, item := range d.Items {
+		items[i] = entity.ItemViewModel{
+			ID:          item.ID,
+			Name:        item.Name,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   entity.NewMoney(item.UnitPrice.Amount, item.UnitPrice.Currency),
+		}
+	}
+
+	return entity.OrderViewModel{
+		ID:           d.ID,
+		CustomerName: d.CustomerName,
+		OrderedAt:    d.OrderedAt,
+		Items:        items,
+		Version:      d.Version,
+	}
+}
+
+// PatchOrder applies an RFC 7396 merge patch or an RFC 6902 JSON patch,
+// chosen by Content-Type, to the order's JSON representation and saves the
+// result like PUT would.
+//
+// Unlike PUT and DELETE, If-Match is optional. A PUT body is a whole order
+// the client built from an earlier read, so saving it without a version
+// check could silently undo other clients' changes. A patch names only the
+// fields it changes and is applied to the order as read here, and the save
+// is conditional on that read's version, so without If-Match a concurrent
+// write fails with 409 instead of being overwritten. Clients that need the
+// patch to apply only to the version they saw send If-Match, and a malformed
+// one is rejected with 400.
+func (h *OrderHandler) PatchOrder(ctx *gin.Context) {
+	var idReq orderByIDRequest
+	if err := ctx.ShouldBindUri(&idReq); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	contentType := ctx.ContentType()
+	if contentType != mimeMergePatch && contentType != mimeJSONPatch {
+		detail := fmt.Sprintf("unsupported Content-Type %q, use %s or %s", contentType, mimeMergePatch, mimeJSONPatch)
+		problemResponse(ctx, http.StatusUnsupportedMediaType, "unsupported_media_type", detail, nil)
+		return
+	}
+
+	patch, err := ctx.GetRawData()
+	if err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	current, err := h.orderService.GetOrder(ctx.Request.Context(), idReq.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	version := current.Version
+	if ctx.GetHeader(ifMatchHeader) != "" {
+		version, err = ifMatchVersion(ctx, func() (int64, error) { return current.Version, nil })
+		if err != nil {
+			ifMatchErrorResponse(ctx, err)
+			return
+		}
+	}
+
+	original, err := json.Marshal(current)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	patched, err := applyPatch(contentType, original, patch)
+	if err != nil {
+		errorResponse(ctx, &apperror.Error{
+			Kind:    apperror.KindValidation,
+			Code:    "invalid_patch",
+			Message: "patch can't be applied",
+			Err:     err,
+		})
+		return
+	}
+
+	var doc orderDocument
+	if err := json.Unmarshal(patched, &doc); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	if doc.ID != current.ID || doc.Owner != current.Owner || doc.Status != current.Status || doc.Version != current.Version {
+		errorResponse(ctx, errReadOnlyField)
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(doc); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	arg := doc.toViewModel()
+	arg.Version = version
+
+	err = h.orderService.UpdateOrder(ctx.Request.Context(), arg)
+	if err != nil {
+		// Without If-Match the client never asserted a version, so losing a
+		// race with another writer is a conflict rather than a failed
+		// precondition.
+		if errors.Is(err, service.ErrVersionMismatch) && ctx.GetHeader(ifMatchHeader) == "" {
+			err = service.ErrConcurrentUpdate
+		}
+
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header(etagHeader, formatETag(version+1))
+	ctx.JSON(http.StatusOK, successResponse())
+}
+
+func applyPatch(contentType string, original []byte, patch []byte) ([]byte, error) {
+	if contentType == mimeMergePatch {
+		return jsonpatch.MergePatch(original, patch)
+	}
+
+	ops, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return ops.Apply(original)
+}
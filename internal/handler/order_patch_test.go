@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/common"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/service"
+	mockService "simple-order-go/internal/service/mock"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchOrder(t *testing.T) {
+	order := randomOrder(true)
+	order.Status = entity.OrderStatusPending
+	for i := range order.Items {
+		order.Items[i].ID = int64(i + 1)
+	}
+	name := common.RandomName()
+
+	testCases := []struct {
+		name          string
+		contentType   string
+		body          string
+		ifMatch       string
+		buildStubs    func(service *mockService.MockIOrderService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "MergePatch",
+			contentType: mimeMergePatch,
+			body:        `{"customer_name":"` + name + `"}`,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg entity.OrderViewModel) error {
+					require.Equal(t, order.ID, arg.ID)
+					require.Equal(t, name, arg.CustomerName)
+					require.Equal(t, order.Version, arg.Version)
+					require.Equal(t, len(order.Items), len(arg.Items))
+					require.Equal(t, order.Items[0].ID, arg.Items[0].ID)
+					return nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, formatETag(order.Version+1), recorder.Header().Get(etagHeader))
+			},
+		},
+		{
+			name:        "JSONPatch",
+			contentType: mimeJSONPatch,
+			body:        `[{"op":"replace","path":"/items/1/quantity","value":42},{"op":"remove","path":"/items/0"}]`,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg entity.OrderViewModel) error {
+					require.Equal(t, order.CustomerName, arg.CustomerName)
+					require.Equal(t, 1, len(arg.Items))
+					require.Equal(t, order.Items[1].ID, arg.Items[0].ID)
+					require.Equal(t, int32(42), arg.Items[0].Quantity)
+					return nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "WithIfMatch",
+			contentType: mimeMergePatch,
+			body:        `{"customer_name":"` + name + `"}`,
+			ifMatch:     formatETag(order.Version - 1),
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				orderService.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg entity.OrderViewModel) error {
+					require.Equal(t, order.Version-1, arg.Version)
+					return service.ErrVersionMismatch
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:        "IfMatchAny",
+			contentType: mimeMergePatch,
+			body:        `{"customer_name":"` + name + `"}`,
+			ifMatch:     "*",
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				orderService.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg entity.OrderViewModel) error {
+					require.Equal(t, order.Version, arg.Version)
+					return nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, formatETag(order.Version+1), recorder.Header().Get(etagHeader))
+			},
+		},
+		{
+			name:        "MalformedIfMatch",
+			contentType: mimeMergePatch,
+			body:        `{"customer_name":"` + name + `"}`,
+			ifMatch:     "not-an-etag",
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				orderService.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "invalid_if_match")
+			},
+		},
+		{
+			name:        "ConcurrentUpdateWithoutIfMatch",
+			contentType: mimeMergePatch,
+			body:        `{"customer_name":"` + name + `"}`,
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				orderService.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).Return(service.ErrVersionMismatch)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusConflict, "concurrent_update")
+			},
+		},
+		{
+			name:        "ReadOnlyField",
+			contentType: mimeMergePatch,
+			body:        `{"status":"shipped"}`,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "ReadOnlyOwner",
+			contentType: mimeMergePatch,
+			body:        `{"owner":"someone-else"}`,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "read_only_field")
+			},
+		},
+		{
+			name:        "InvalidResult",
+			contentType: mimeJSONPatch,
+			body:        `[{"op":"replace","path":"/items/0/quantity","value":0}]`,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "MalformedPatch",
+			contentType: mimeJSONPatch,
+			body:        `{"customer_name":"` + name + `"}`,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "UnsupportedMediaType",
+			contentType: "application/json",
+			body:        `{"customer_name":"` + name + `"}`,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+		{
+			name:        "NotFound",
+			contentType: mimeMergePatch,
+			body:        `{"customer_name":"` + name + `"}`,
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(entity.OrderViewModel{}, service.ErrOrderNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "PATCH"}
+			mockRequest(ctx, nil, order.ID)
+			ctx.Request.Header.Set("Content-Type", tc.contentType)
+			ctx.Request.Body = io.NopCloser(bytes.NewBufferString(tc.body))
+			if tc.ifMatch != "" {
+				ctx.Request.Header.Set(ifMatchHeader, tc.ifMatch)
+			}
+
+			handler, service := setUpHandler(t)
+			tc.buildStubs(service)
+
+			handler.PatchOrder(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,20 +10,23 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"simple-order-go/common"
 	"simple-order-go/internal/entity"
+	"simple-order-go/internal/service"
 	mockService "simple-order-go/internal/service/mock"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
-	"github.com/jackc/pgx"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCreateOrder(t *testing.T) {
 	order := randomOrder(false)
+	negative := decimal.NewFromInt(-1)
 	fmt.Println("order: ", order)
 
 	testCases := []struct {
@@ -38,19 +42,23 @@ func TestCreateOrder(t *testing.T) {
 				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
 				Items: []itemRequest{
 					{
-						Name:     order.Items[0].Name,
-						Desc:     order.Items[0].Description,
-						Quantity: order.Items[0].Quantity,
+						Name:      order.Items[0].Name,
+						Desc:      order.Items[0].Description,
+						Quantity:  order.Items[0].Quantity,
+						UnitPrice: &order.Items[0].UnitPrice.Amount,
+						Currency:  order.Items[0].UnitPrice.Currency,
 					},
 					{
-						Name:     order.Items[1].Name,
-						Desc:     order.Items[1].Description,
-						Quantity: order.Items[1].Quantity,
+						Name:      order.Items[1].Name,
+						Desc:      order.Items[1].Description,
+						Quantity:  order.Items[1].Quantity,
+						UnitPrice: &order.Items[1].UnitPrice.Amount,
+						Currency:  order.Items[1].UnitPrice.Currency,
 					},
 				},
 			},
 			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().CreateOrder(order).Times(1).Return(order, nil)
+				service.EXPECT().CreateOrder(gomock.Any(), order).Times(1).Return(order, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				t.Log("recorder: ", recorder)
@@ -58,6 +66,73 @@ func TestCreateOrder(t *testing.T) {
 				requireBodyMatchOrder(t, recorder.Body, order)
 			},
 		},
+		{
+			name: "MissingUnitPrice",
+			body: requiredOrderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+				Items: []itemRequest{
+					{
+						Name:     order.Items[0].Name,
+						Desc:     order.Items[0].Description,
+						Quantity: order.Items[0].Quantity,
+						Currency: order.Items[0].UnitPrice.Currency,
+					},
+				},
+			},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+			},
+		},
+		{
+			name: "InvalidOrder",
+			body: requiredOrderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+				Items: []itemRequest{
+					{
+						Name:      order.Items[0].Name,
+						Desc:      order.Items[0].Description,
+						Quantity:  order.Items[0].Quantity,
+						UnitPrice: &negative,
+						Currency:  order.Items[0].UnitPrice.Currency,
+					},
+				},
+			},
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Times(1).Return(entity.OrderViewModel{}, service.ErrInvalidOrder)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "invalid_order")
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			body: requiredOrderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+				Items: []itemRequest{
+					{
+						Name:      order.Items[0].Name,
+						Desc:      order.Items[0].Description,
+						Quantity:  order.Items[0].Quantity,
+						UnitPrice: &order.Items[0].UnitPrice.Amount,
+						Currency:  "usd",
+					},
+				},
+			},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				problem := requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Equal(t, "items[0].currency", problem.Errors[0].Field)
+				require.Equal(t, "iso4217", problem.Errors[0].Code)
+			},
+		},
 		{
 			name: "MissingRequiredData",
 			body: requiredOrderRequest{
@@ -65,11 +140,36 @@ func TestCreateOrder(t *testing.T) {
 				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
 			},
 			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().CreateOrder(order).Times(0)
+				service.EXPECT().CreateOrder(gomock.Any(), order).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				t.Log("recorder: ", recorder)
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Equal(t, "items", problem.Errors[0].Field)
+				require.Equal(t, "required", problem.Errors[0].Code)
+			},
+		},
+		{
+			name: "InvalidOrderedAt",
+			body: requiredOrderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    "yesterday",
+				Items: []itemRequest{
+					{
+						Name:      order.Items[0].Name,
+						Desc:      order.Items[0].Description,
+						Quantity:  order.Items[0].Quantity,
+						UnitPrice: &order.Items[0].UnitPrice.Amount,
+						Currency:  order.Items[0].UnitPrice.Currency,
+					},
+				},
+			},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				problem := requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Equal(t, "orderedAt", problem.Errors[0].Field)
 			},
 		},
 	}
@@ -106,27 +206,28 @@ func TestGetOrderByID(t *testing.T) {
 			name:  "OK",
 			param: order.ID,
 			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().GetOrder(order.ID).Times(1).Return(order, nil)
+				service.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, formatETag(order.Version), recorder.Header().Get(etagHeader))
 				requireBodyMatchOrder(t, recorder.Body, order)
 			},
 		},
 		{
 			name:  "NotFound",
 			param: order.ID,
-			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().GetOrder(order.ID).Times(1).Return(entity.OrderViewModel{}, pgx.ErrNoRows)
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(entity.OrderViewModel{}, service.ErrOrderNotFound)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblem(t, recorder, http.StatusNotFound, "order_not_found")
 			},
 		},
 		{
 			name: "NoParam",
 			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().CreateOrder(order).Times(0)
+				service.EXPECT().CreateOrder(gomock.Any(), order).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -153,42 +254,189 @@ func TestGetOrderByID(t *testing.T) {
 	}
 }
 
+func TestGetAllOrders(t *testing.T) {
+	page := entity.OrderPage{
+		Data:       []entity.OrderViewModel{randomOrder(true), randomOrder(true)},
+		NextCursor: common.RandomString(12),
+	}
+	from := randomOrder(false).OrderedAt
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(service *mockService.MockIOrderService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "limit=2",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrdersPage(gomock.Any(), entity.OrderQuery{}, entity.PageRequest{Limit: 2}).Times(1).Return(page, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotPage entity.OrderPage
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotPage)
+				require.NoError(t, err)
+				require.Equal(t, len(page.Data), len(gotPage.Data))
+				require.Equal(t, page.NextCursor, gotPage.NextCursor)
+			},
+		},
+		{
+			name:  "WithCursor",
+			query: "cursor=abc",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrdersPage(gomock.Any(), entity.OrderQuery{}, entity.PageRequest{Cursor: "abc"}).Times(1).Return(entity.OrderPage{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "WithFilterAndSort",
+			query: "customer_name_prefix=ab&ordered_from=" + url.QueryEscape(common.ParseTimeToString(from)) + "&min_quantity=5&sort=ordered_at&order=desc",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				query := entity.OrderQuery{
+					Filter: entity.OrderFilter{
+						CustomerNamePrefix: "ab",
+						OrderedFrom:        &from,
+						MinItemQuantity:    5,
+					},
+					Sort: entity.OrderSort{Field: entity.OrderSortOrderedAt, Desc: true},
+				}
+				service.EXPECT().GetOrdersPage(gomock.Any(), query, entity.PageRequest{}).Times(1).Return(page, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "IncludeDeleted",
+			query: "include_deleted=true",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				query := entity.OrderQuery{Filter: entity.OrderFilter{IncludeDeleted: true}}
+				service.EXPECT().GetOrdersPage(gomock.Any(), query, entity.PageRequest{}).Times(1).Return(page, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidSortField",
+			query: "sort=quantity",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrdersPage(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidOrderedFrom",
+			query: "ordered_from=yesterday",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrdersPage(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidQuery",
+			query: "sort=ordered_at",
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrdersPage(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(entity.OrderPage{}, service.ErrInvalidQuery)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidLimit",
+			query: "limit=1000",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrdersPage(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "cursor=abc",
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrdersPage(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(entity.OrderPage{}, service.ErrInvalidCursor)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/orders?"+tc.query, nil)
+
+			handler, service := setUpHandler(t)
+			tc.buildStubs(service)
+
+			handler.GetAllOrders(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
 func TestUpdateOrder(t *testing.T) {
 	order := randomOrder(true)
+	itemID := common.RandomInt(1, 99)
 
 	testCases := []struct {
 		name          string
 		body          orderRequest
+		ifMatch       string
 		buildStubs    func(service *mockService.MockIOrderService)
 		checkResponse func(recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name: "OK",
+			name:    "OK",
+			ifMatch: formatETag(order.Version),
 			body: orderRequest{
 				CustomerName: order.CustomerName,
 				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
 				Items: []itemRequest{
 					{
-						Name:     order.Items[0].Name,
-						Desc:     order.Items[0].Description,
-						Quantity: order.Items[0].Quantity,
+						Name:      order.Items[0].Name,
+						Desc:      order.Items[0].Description,
+						Quantity:  order.Items[0].Quantity,
+						UnitPrice: &order.Items[0].UnitPrice.Amount,
+						Currency:  order.Items[0].UnitPrice.Currency,
 					},
 					{
-						Name:     order.Items[1].Name,
-						Desc:     order.Items[1].Description,
-						Quantity: order.Items[1].Quantity,
+						Name:      order.Items[1].Name,
+						Desc:      order.Items[1].Description,
+						Quantity:  order.Items[1].Quantity,
+						UnitPrice: &order.Items[1].UnitPrice.Amount,
+						Currency:  order.Items[1].UnitPrice.Currency,
 					},
 				},
 			},
 			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().UpdateOrder(order).Times(1).Return(nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), order).Times(1).Return(nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, formatETag(order.Version+1), recorder.Header().Get(etagHeader))
 			},
 		},
 		{
-			name: "WithoutItems",
+			name:    "WithoutItems",
+			ifMatch: formatETag(order.Version),
 			body: orderRequest{
 				CustomerName: order.CustomerName,
 				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
@@ -199,8 +447,9 @@ func TestUpdateOrder(t *testing.T) {
 					CustomerName: order.CustomerName,
 					OrderedAt:    order.OrderedAt,
 					Items:        []entity.ItemViewModel{},
+					Version:      order.Version,
 				}
-				service.EXPECT().UpdateOrder(arg).Times(1).Return(nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), arg).Times(1).Return(nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				fmt.Println("recorder: ", recorder)
@@ -208,12 +457,162 @@ func TestUpdateOrder(t *testing.T) {
 			},
 		},
 		{
-			name: "MissingRequiredData",
+			name:    "WithItemIDs",
+			ifMatch: formatETag(order.Version),
+			body: orderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+				Items: []itemRequest{
+					{
+						ID:        itemID,
+						Name:      order.Items[0].Name,
+						Desc:      order.Items[0].Description,
+						Quantity:  order.Items[0].Quantity,
+						UnitPrice: &order.Items[0].UnitPrice.Amount,
+						Currency:  order.Items[0].UnitPrice.Currency,
+					},
+				},
+			},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				item := order.Items[0]
+				item.ID = itemID
+				arg := order
+				arg.Items = []entity.ItemViewModel{item}
+				service.EXPECT().UpdateOrder(gomock.Any(), arg).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "UnknownItem",
+			ifMatch: formatETag(order.Version),
+			body: orderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+			},
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).Return(service.ErrInvalidOrder)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "VersionMismatch",
+			ifMatch: formatETag(order.Version + 1),
+			body: orderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+			},
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).Return(service.ErrVersionMismatch)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "NotFound",
+			ifMatch: formatETag(order.Version),
+			body: orderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+			},
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).Return(service.ErrOrderNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "order_not_found")
+			},
+		},
+		{
+			name:    "IfMatchAny",
+			ifMatch: "*",
+			body: orderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+			},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg entity.OrderViewModel) error {
+					require.Equal(t, order.Version, arg.Version)
+					return nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, formatETag(order.Version+1), recorder.Header().Get(etagHeader))
+			},
+		},
+		{
+			name:    "IfMatchAnyNotFound",
+			ifMatch: "*",
+			body: orderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+			},
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(entity.OrderViewModel{}, service.ErrOrderNotFound)
+				orderService.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "order_not_found")
+			},
+		},
+		{
+			name:    "IfMatchList",
+			ifMatch: formatETag(order.Version-1) + ", " + formatETag(order.Version),
 			body: orderRequest{
 				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
 			},
 			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().UpdateOrder(gomock.Any()).Times(0)
+				service.EXPECT().GetOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg entity.OrderViewModel) error {
+					require.Equal(t, order.Version, arg.Version)
+					return nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "MalformedIfMatch",
+			ifMatch: "3",
+			body: orderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+			},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "invalid_if_match")
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			body: orderRequest{
+				CustomerName: order.CustomerName,
+				OrderedAt:    common.ParseTimeToString(order.OrderedAt),
+			},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:    "MissingRequiredData",
+			ifMatch: formatETag(order.Version),
+			body: orderRequest{
+				CustomerName: order.CustomerName,
+			},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -230,6 +629,9 @@ func TestUpdateOrder(t *testing.T) {
 
 			ctx.Request = &http.Request{Header: make(http.Header), Method: "PUT"}
 			mockRequest(ctx, tc.body, order.ID)
+			if tc.ifMatch != "" {
+				ctx.Request.Header.Set(ifMatchHeader, tc.ifMatch)
+			}
 
 			handler, service := setUpHandler(t)
 			tc.buildStubs(service)
@@ -242,37 +644,119 @@ func TestUpdateOrder(t *testing.T) {
 
 func TestDeleteOrde(t *testing.T) {
 	var orderID int64 = 1
+	var version int64 = 3
 
 	testCases := []struct {
 		name          string
 		param         int64
+		ifMatch       string
 		buildStubs    func(service *mockService.MockIOrderService)
 		checkResponse func(recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name:  "OK",
-			param: orderID,
+			name:    "OK",
+			param:   orderID,
+			ifMatch: formatETag(version),
 			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().DeleteOrder(orderID).Times(1).Return(nil)
+				service.EXPECT().DeleteOrder(gomock.Any(), orderID, version).Times(1).Return(nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 		{
-			name:  "NotFound",
+			name:    "NotFound",
+			param:   orderID,
+			ifMatch: formatETag(version),
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().DeleteOrder(gomock.Any(), orderID, version).Times(1).Return(service.ErrOrderNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "order_not_found")
+			},
+		},
+		{
+			name:    "Forbidden",
+			param:   orderID,
+			ifMatch: formatETag(version),
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().DeleteOrder(gomock.Any(), orderID, version).Times(1).Return(service.ErrForbidden)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusForbidden, "forbidden")
+			},
+		},
+		{
+			name:    "VersionMismatch",
+			param:   orderID,
+			ifMatch: formatETag(version),
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().DeleteOrder(gomock.Any(), orderID, version).Times(1).Return(service.ErrVersionMismatch)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "MalformedIfMatch",
+			param:   orderID,
+			ifMatch: "W/" + formatETag(version),
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().DeleteOrder(gomock.Any(), orderID, int64(0)).Times(1).Return(service.ErrVersionMismatch)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "IfMatchAny",
+			param:   orderID,
+			ifMatch: "*",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrder(gomock.Any(), orderID).Times(1).Return(entity.OrderViewModel{ID: orderID, Version: version}, nil)
+				service.EXPECT().DeleteOrder(gomock.Any(), orderID, version).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "IfMatchListMismatch",
+			param:   orderID,
+			ifMatch: formatETag(version-2) + "," + formatETag(version-1),
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrder(gomock.Any(), orderID).Times(1).Return(entity.OrderViewModel{ID: orderID, Version: version}, nil)
+				orderService.EXPECT().DeleteOrder(gomock.Any(), orderID, int64(0)).Times(1).Return(service.ErrVersionMismatch)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "UnparsableIfMatch",
+			param:   orderID,
+			ifMatch: `"3`,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().DeleteOrder(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "invalid_if_match")
+			},
+		},
+		{
+			name:  "MissingIfMatch",
 			param: orderID,
 			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().DeleteOrder(orderID).Times(1).Return(pgx.ErrNoRows)
+				service.EXPECT().DeleteOrder(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
 			},
 		},
 		{
 			name: "NoParam",
 			buildStubs: func(service *mockService.MockIOrderService) {
-				service.EXPECT().CreateOrder(orderID).Times(0)
+				service.EXPECT().CreateOrder(gomock.Any(), orderID).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -289,6 +773,9 @@ func TestDeleteOrde(t *testing.T) {
 
 			ctx.Request = &http.Request{Header: make(http.Header), Method: "DELETE"}
 			mockRequest(ctx, nil, tc.param)
+			if tc.ifMatch != "" {
+				ctx.Request.Header.Set(ifMatchHeader, tc.ifMatch)
+			}
 
 			handler, service := setUpHandler(t)
 			tc.buildStubs(service)
@@ -299,6 +786,296 @@ func TestDeleteOrde(t *testing.T) {
 	}
 }
 
+func TestRestoreOrder(t *testing.T) {
+	order := randomOrder(true)
+
+	testCases := []struct {
+		name          string
+		param         int64
+		buildStubs    func(service *mockService.MockIOrderService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			param: order.ID,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().RestoreOrder(gomock.Any(), order.ID).Times(1).Return(order, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, formatETag(order.Version), recorder.Header().Get(etagHeader))
+				requireBodyMatchOrder(t, recorder.Body, order)
+			},
+		},
+		{
+			name:  "NotFound",
+			param: order.ID,
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().RestoreOrder(gomock.Any(), order.ID).Times(1).Return(entity.OrderViewModel{}, service.ErrOrderNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "order_not_found")
+			},
+		},
+		{
+			name:  "NotDeleted",
+			param: order.ID,
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().RestoreOrder(gomock.Any(), order.ID).Times(1).Return(entity.OrderViewModel{}, service.ErrOrderNotDeleted)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusConflict, "order_not_deleted")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "POST"}
+			mockRequest(ctx, nil, tc.param)
+
+			handler, service := setUpHandler(t)
+			tc.buildStubs(service)
+
+			handler.RestoreOrder(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestPurgeOrders(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(service *mockService.MockIOrderService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "older_than=720h",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().PurgeOrders(gomock.Any(), 720*time.Hour).Times(1).Return(int64(3), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"purged":3}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "MissingOlderThan",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().PurgeOrders(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+			},
+		},
+		{
+			name:  "InvalidOlderThan",
+			query: "older_than=30d",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().PurgeOrders(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				problem := requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Equal(t, "older_than", problem.Errors[0].Field)
+			},
+		},
+		{
+			name:  "InvalidRetention",
+			query: "older_than=-1h",
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().PurgeOrders(gomock.Any(), -time.Hour).Times(1).Return(int64(0), service.ErrInvalidRetention)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "invalid_retention")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/admin/orders/purge?"+tc.query, nil)
+
+			handler, service := setUpHandler(t)
+			tc.buildStubs(service)
+
+			handler.PurgeOrders(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestTransitionOrder(t *testing.T) {
+	order := randomOrder(true)
+	order.Status = entity.OrderStatusConfirmed
+
+	testCases := []struct {
+		name          string
+		body          transitionRequest
+		buildStubs    func(service *mockService.MockIOrderService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: transitionRequest{Status: string(entity.OrderStatusConfirmed)},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().TransitionOrder(gomock.Any(), order.ID, entity.OrderStatusConfirmed).Times(1).Return(order, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchOrder(t, recorder.Body, order)
+			},
+		},
+		{
+			name: "UnknownStatus",
+			body: transitionRequest{Status: "lost"},
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().TransitionOrder(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "IllegalTransition",
+			body: transitionRequest{Status: string(entity.OrderStatusDelivered)},
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().TransitionOrder(gomock.Any(), order.ID, entity.OrderStatusDelivered).Times(1).
+					Return(entity.OrderViewModel{}, service.ErrInvalidTransition)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Forbidden",
+			body: transitionRequest{Status: string(entity.OrderStatusPaid)},
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().TransitionOrder(gomock.Any(), order.ID, entity.OrderStatusPaid).Times(1).
+					Return(entity.OrderViewModel{}, service.ErrForbidden)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusForbidden, "forbidden")
+			},
+		},
+		{
+			name: "NotFound",
+			body: transitionRequest{Status: string(entity.OrderStatusConfirmed)},
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().TransitionOrder(gomock.Any(), order.ID, entity.OrderStatusConfirmed).Times(1).
+					Return(entity.OrderViewModel{}, service.ErrOrderNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "POST"}
+			mockRequest(ctx, tc.body, order.ID)
+
+			handler, service := setUpHandler(t)
+			tc.buildStubs(service)
+
+			handler.TransitionOrder(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestGetOrderHistory(t *testing.T) {
+	orderID := common.RandomInt(1, 1000)
+	history := []entity.AuditEntryViewModel{
+		{
+			ID:        1,
+			Action:    entity.AuditActionCreated,
+			Actor:     "anonymous",
+			RequestID: common.RandomString(16),
+			Changes:   json.RawMessage(`[{"path":"customer_name","from":null,"to":"bob"}]`),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		param         int64
+		buildStubs    func(service *mockService.MockIOrderService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			param: orderID,
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrderHistory(gomock.Any(), orderID).Times(1).Return(history, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []entity.AuditEntryViewModel
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, 1, len(got))
+				require.Equal(t, history[0].RequestID, got[0].RequestID)
+				require.JSONEq(t, string(history[0].Changes), string(got[0].Changes))
+			},
+		},
+		{
+			name:  "NotFound",
+			param: orderID,
+			buildStubs: func(orderService *mockService.MockIOrderService) {
+				orderService.EXPECT().GetOrderHistory(gomock.Any(), orderID).Times(1).Return(nil, service.ErrOrderNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "order_not_found")
+			},
+		},
+		{
+			name: "InvalidID",
+			buildStubs: func(service *mockService.MockIOrderService) {
+				service.EXPECT().GetOrderHistory(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "GET"}
+			mockRequest(ctx, nil, tc.param)
+
+			handler, service := setUpHandler(t)
+			tc.buildStubs(service)
+
+			handler.GetOrderHistory(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
 func requireBodyMatchOrder(t *testing.T, body *bytes.Buffer, order entity.OrderViewModel) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
@@ -311,6 +1088,7 @@ func requireBodyMatchOrder(t *testing.T, body *bytes.Buffer, order entity.OrderV
 	require.Equal(t, order.CustomerName, gotOrder.CustomerName)
 	require.WithinDuration(t, order.OrderedAt, gotOrder.OrderedAt, time.Second)
 	require.Equal(t, len(order.Items), len(gotOrder.Items))
+	require.True(t, order.Total.Amount.Equal(gotOrder.Total.Amount))
 }
 
 func randomOrder(withID bool) entity.OrderViewModel {
@@ -327,9 +1105,10 @@ func randomOrder(withID bool) entity.OrderViewModel {
 		log.Fatal("Couldn't parse string to time: ", err)
 	}
 
-	var id int64 = 0
+	var id, version int64 = 0, 0
 	if withID {
 		id = common.RandomInt(1, 99)
+		version = common.RandomInt(1, 10)
 	}
 
 	order := entity.OrderViewModel{
@@ -337,6 +1116,7 @@ func randomOrder(withID bool) entity.OrderViewModel {
 		CustomerName: common.RandomName(),
 		OrderedAt:    time,
 		Items:        items,
+		Version:      version,
 	}
 
 	return order
@@ -347,6 +1127,7 @@ func randomItem() entity.ItemViewModel {
 		Name:        common.RandomName(),
 		Description: common.RandomString(10),
 		Quantity:    int32(common.RandomInt(1, 100)),
+		UnitPrice:   entity.NewMoney(decimal.NewFromInt(common.RandomInt(1, 1000)), "USD"),
 	}
 
 	return item
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"simple-order-go/internal/apperror"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const mimeProblem = "application/problem+json"
+
+// problem is an RFC 7807 problem details document, extended with a stable
+// error code and per-field validation errors.
+type problem struct {
+	Type   string                `json:"type"`
+	Title  string                `json:"title"`
+	Status int                   `json:"status"`
+	Detail string                `json:"detail,omitempty"`
+	Code   string                `json:"code"`
+	Errors []apperror.FieldError `json:"errors,omitempty"`
+}
+
+var kindStatus = map[apperror.Kind]int{
+	apperror.KindNotFound:        http.StatusNotFound,
+	apperror.KindValidation:      http.StatusBadRequest,
+	apperror.KindUnprocessable:   http.StatusUnprocessableEntity,
+	apperror.KindConflict:        http.StatusConflict,
+	apperror.KindPrecondition:    http.StatusPreconditionFailed,
+	apperror.KindUnauthenticated: http.StatusUnauthorized,
+	apperror.KindForbidden:       http.StatusForbidden,
+	apperror.KindTooManyRequests: http.StatusTooManyRequests,
+}
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(requestFieldName)
+	}
+}
+
+// requestFieldName names struct fields in validation errors the way clients
+// spell them.
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// statusClientClosedRequest is the non-standard status, taken from nginx,
+// recorded for requests the client gave up on before they were answered.
+const statusClientClosedRequest = 499
+
+// errorResponse writes err as a problem document. The status is derived from
+// the apperror kind; errors of any other type are reported as internal
+// errors without exposing their message. A request that failed because the
+// client went away gets 499 without a body, since nobody reads it, and isn't
+// reported as an error of the server.
+func errorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, context.Canceled) {
+		ctx.AbortWithStatus(statusClientClosedRequest)
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		problemResponse(ctx, http.StatusGatewayTimeout, "timeout", "the request took too long to complete", nil)
+		return
+	}
+
+	appErr := apperror.As(err)
+
+	status, ok := kindStatus[appErr.Kind]
+	if !ok {
+		_ = ctx.Error(err)
+		problemResponse(ctx, http.StatusInternalServerError, appErr.Code, "", nil)
+		return
+	}
+
+	problemResponse(ctx, status, appErr.Code, err.Error(), appErr.Fields)
+}
+
+// AbortWithError is errorResponse for middleware outside this package, so
+// that every error the API returns has the same format.
+func AbortWithError(ctx *gin.Context, err error) {
+	errorResponse(ctx, err)
+}
+
+// bindingErrorResponse reports a request that couldn't be bound or failed
+// validation.
+func bindingErrorResponse(ctx *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]apperror.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = apperror.FieldError{
+				Field:   fieldPath(fe),
+				Code:    fe.Tag(),
+				Message: validationMessage(fe),
+			}
+		}
+
+		errorResponse(ctx, apperror.Validation("validation_failed", "request validation failed", fields...))
+		return
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		errorResponse(ctx, &apperror.Error{
+			Kind:    apperror.KindValidation,
+			Code:    "malformed_request",
+			Message: "request body is not valid JSON",
+			Err:     err,
+		})
+		return
+	}
+
+	errorResponse(ctx, &apperror.Error{
+		Kind:    apperror.KindValidation,
+		Code:    "invalid_request",
+		Message: "invalid request",
+		Err:     err,
+	})
+}
+
+// preconditionRequiredResponse reports a conditional request that is missing
+// its If-Match header.
+func preconditionRequiredResponse(ctx *gin.Context, err error) {
+	problemResponse(ctx, http.StatusPreconditionRequired, "if_match_required", err.Error(), nil)
+}
+
+// ifMatchErrorResponse reports an If-Match header that is missing or
+// malformed, or the error looking up the version it is matched against.
+func ifMatchErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errIfMatchRequired):
+		preconditionRequiredResponse(ctx, err)
+	case errors.Is(err, errIfMatchMalformed):
+		problemResponse(ctx, http.StatusBadRequest, "invalid_if_match", err.Error(), nil)
+	default:
+		errorResponse(ctx, err)
+	}
+}
+
+func problemResponse(ctx *gin.Context, status int, code string, detail string, fields []apperror.FieldError) {
+	ctx.Header("Content-Type", mimeProblem)
+	ctx.AbortWithStatusJSON(status, problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Errors: fields,
+	})
+}
+
+// fieldPath drops the request struct's name from a validation error's
+// namespace, leaving e.g. "items[0].quantity".
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return ns
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_with":
+		return "is required together with " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "lte":
+		return "must be at most " + fe.Param()
+	case "min":
+		return "must have a length of at least " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	}
+	return fmt.Sprintf("failed the %q rule", fe.Tag())
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/apperror"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponse(t *testing.T) {
+	testCases := []struct {
+		name          string
+		err           error
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NotFound",
+			err:  apperror.NotFound("order_not_found", "order not found"),
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				problem := requireProblem(t, recorder, http.StatusNotFound, "order_not_found")
+				require.Equal(t, "order not found", problem.Detail)
+			},
+		},
+		{
+			name: "Validation",
+			err: apperror.Validation("validation_failed", "request validation failed", apperror.FieldError{
+				Field:   "customerName",
+				Code:    "required",
+				Message: "is required",
+			}),
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				problem := requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Len(t, problem.Errors, 1)
+				require.Equal(t, "customerName", problem.Errors[0].Field)
+			},
+		},
+		{
+			name: "Unprocessable",
+			err:  apperror.Unprocessable("idempotency_key_reused", "key reused"),
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusUnprocessableEntity, "idempotency_key_reused")
+			},
+		},
+		{
+			name: "Conflict",
+			err:  apperror.Conflict("invalid_transition", "can't transition"),
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusConflict, "invalid_transition")
+			},
+		},
+		{
+			name: "Precondition",
+			err:  apperror.Precondition("version_mismatch", "version moved"),
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusPreconditionFailed, "version_mismatch")
+			},
+		},
+		{
+			name: "Timeout",
+			err:  fmt.Errorf("get order: %w", context.DeadlineExceeded),
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusGatewayTimeout, "timeout")
+			},
+		},
+		{
+			name: "Internal",
+			err:  errors.New(`pq: relation "orders" does not exist`),
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				problem := requireProblem(t, recorder, http.StatusInternalServerError, "internal")
+				require.Empty(t, problem.Detail)
+				require.NotContains(t, recorder.Body.String(), "orders")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			errorResponse(ctx, tc.err)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestErrorResponseClientGone(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	errorResponse(ctx, fmt.Errorf("get order: %w", context.Canceled))
+	require.Equal(t, statusClientClosedRequest, w.Code)
+	require.Empty(t, w.Body.String())
+	require.Empty(t, ctx.Errors)
+	require.True(t, ctx.IsAborted())
+}
+
+func requireProblem(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) problem {
+	require.Equal(t, status, recorder.Code)
+	require.Equal(t, mimeProblem, recorder.Header().Get("Content-Type"))
+
+	var got problem
+	err := json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, status, got.Status)
+	require.Equal(t, http.StatusText(status), got.Title)
+	require.Equal(t, code, got.Code)
+
+	return got
+}
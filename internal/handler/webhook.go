@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"net/http"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService service.IWebhookService
+}
+
+func NewWebhookHandler(webhookService service.IWebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+type webhookRequest struct {
+	URL        string   `json:"url" binding:"required,http_url"`
+	EventTypes []string `json:"event_types" binding:"required,gt=0,dive,oneof=order.created order.updated order.deleted order.restored order.status_changed item.added item.updated item.deleted"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16"`
+}
+
+type webhookByIDRequest struct {
+	ID int64 `uri:"id" binding:"required,gt=0"`
+}
+
+type deliveryByIDRequest struct {
+	ID         int64 `uri:"id" binding:"required,gt=0"`
+	DeliveryID int64 `uri:"deliveryId" binding:"required,gt=0"`
+}
+
+// CreateWebhook subscribes an endpoint to events. The response carries the
+// secret deliveries are signed with; it isn't shown again.
+func (h *WebhookHandler) CreateWebhook(ctx *gin.Context) {
+	var req webhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	eventTypes := make([]entity.EventType, len(req.EventTypes))
+	for i, eventType := range req.EventTypes {
+		eventTypes[i] = entity.EventType(eventType)
+	}
+
+	arg := entity.WebhookSubscriptionViewModel{
+		URL:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     req.Secret,
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(ctx.Request.Context(), arg)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) GetWebhooks(ctx *gin.Context) {
+	subscriptions, err := h.webhookService.GetSubscriptions(ctx.Request.Context())
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) GetWebhook(ctx *gin.Context) {
+	var req webhookByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	subscription, err := h.webhookService.GetSubscription(ctx.Request.Context(), req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) DeleteWebhook(ctx *gin.Context) {
+	var req webhookByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	err := h.webhookService.DeleteSubscription(ctx.Request.Context(), req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, successResponse())
+}
+
+func (h *WebhookHandler) GetDeliveries(ctx *gin.Context) {
+	var req webhookByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(ctx.Request.Context(), req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// GetDelivery returns a delivery along with the log of its attempts.
+func (h *WebhookHandler) GetDelivery(ctx *gin.Context) {
+	var req deliveryByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(ctx.Request.Context(), req.ID, req.DeliveryID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, delivery)
+}
+
+func (h *WebhookHandler) RedeliverDelivery(ctx *gin.Context) {
+	var req deliveryByIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		bindingErrorResponse(ctx, err)
+		return
+	}
+
+	delivery, err := h.webhookService.RedeliverDelivery(ctx.Request.Context(), req.ID, req.DeliveryID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/service"
+	mockService "simple-order-go/internal/service/mock"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	subscription := randomSubscription()
+	arg := entity.WebhookSubscriptionViewModel{URL: subscription.URL, EventTypes: subscription.EventTypes}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(service *mockService.MockIWebhookService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"url": subscription.URL, "event_types": subscription.EventTypes},
+			buildStubs: func(service *mockService.MockIWebhookService) {
+				service.EXPECT().CreateSubscription(gomock.Any(), arg).Times(1).Return(subscription, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got entity.WebhookSubscriptionViewModel
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, subscription.ID, got.ID)
+				require.Equal(t, subscription.Secret, got.Secret)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: gin.H{"url": "not a url", "event_types": subscription.EventTypes},
+			buildStubs: func(service *mockService.MockIWebhookService) {
+				service.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				got := requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Equal(t, "url", got.Errors[0].Field)
+			},
+		},
+		{
+			name: "UnknownEventType",
+			body: gin.H{"url": subscription.URL, "event_types": []string{"order.shipped"}},
+			buildStubs: func(service *mockService.MockIWebhookService) {
+				service.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				got := requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+				require.Equal(t, "event_types[0]", got.Errors[0].Field)
+			},
+		},
+		{
+			name: "NoEventTypes",
+			body: gin.H{"url": subscription.URL, "event_types": []string{}},
+			buildStubs: func(service *mockService.MockIWebhookService) {
+				service.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+			},
+		},
+		{
+			name: "ShortSecret",
+			body: gin.H{"url": subscription.URL, "event_types": subscription.EventTypes, "secret": "short"},
+			buildStubs: func(service *mockService.MockIWebhookService) {
+				service.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusBadRequest, "validation_failed")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "POST"}
+			mockRequest(ctx, tc.body, 0)
+
+			handler, service := setUpWebhookHandler(t)
+			tc.buildStubs(service)
+
+			handler.CreateWebhook(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	var subscriptionID int64 = 1
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(service *mockService.MockIWebhookService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(service *mockService.MockIWebhookService) {
+				service.EXPECT().DeleteSubscription(gomock.Any(), subscriptionID).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(webhookService *mockService.MockIWebhookService) {
+				webhookService.EXPECT().DeleteSubscription(gomock.Any(), subscriptionID).Times(1).Return(service.ErrSubscriptionNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "webhook_not_found")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "DELETE"}
+			mockRequest(ctx, nil, subscriptionID)
+
+			handler, service := setUpWebhookHandler(t)
+			tc.buildStubs(service)
+
+			handler.DeleteWebhook(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestGetDelivery(t *testing.T) {
+	delivery := randomDelivery()
+
+	testCases := []struct {
+		name          string
+		deliveryID    int64
+		buildStubs    func(service *mockService.MockIWebhookService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			deliveryID: delivery.ID,
+			buildStubs: func(service *mockService.MockIWebhookService) {
+				service.EXPECT().GetDelivery(gomock.Any(), delivery.SubscriptionID, delivery.ID).Times(1).Return(delivery, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got entity.WebhookDeliveryViewModel
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, delivery.ID, got.ID)
+				require.Equal(t, entity.DeliveryDead, got.Status)
+				require.Equal(t, len(delivery.Log), len(got.Log))
+			},
+		},
+		{
+			name:       "NotFound",
+			deliveryID: delivery.ID,
+			buildStubs: func(webhookService *mockService.MockIWebhookService) {
+				webhookService.EXPECT().GetDelivery(gomock.Any(), delivery.SubscriptionID, delivery.ID).Times(1).
+					Return(entity.WebhookDeliveryViewModel{}, service.ErrDeliveryNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "delivery_not_found")
+			},
+		},
+		{
+			name: "NoDeliveryParam",
+			buildStubs: func(service *mockService.MockIWebhookService) {
+				service.EXPECT().GetDelivery(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "GET"}
+			mockRequest(ctx, nil, delivery.SubscriptionID)
+			if tc.deliveryID != 0 {
+				ctx.Params = append(ctx.Params, gin.Param{Key: "deliveryId", Value: strconv.FormatInt(tc.deliveryID, 10)})
+			}
+
+			handler, service := setUpWebhookHandler(t)
+			tc.buildStubs(service)
+
+			handler.GetDelivery(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func TestRedeliverDelivery(t *testing.T) {
+	delivery := randomDelivery()
+	redelivered := delivery
+	redelivered.Status = entity.DeliveryPending
+	redelivered.Attempts = 0
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(service *mockService.MockIWebhookService)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(service *mockService.MockIWebhookService) {
+				service.EXPECT().RedeliverDelivery(gomock.Any(), delivery.SubscriptionID, delivery.ID).Times(1).Return(redelivered, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var got entity.WebhookDeliveryViewModel
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, entity.DeliveryPending, got.Status)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(webhookService *mockService.MockIWebhookService) {
+				webhookService.EXPECT().RedeliverDelivery(gomock.Any(), delivery.SubscriptionID, delivery.ID).Times(1).
+					Return(entity.WebhookDeliveryViewModel{}, service.ErrDeliveryNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, "delivery_not_found")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = &http.Request{Header: make(http.Header), Method: "POST"}
+			mockRequest(ctx, nil, delivery.SubscriptionID)
+			ctx.Params = append(ctx.Params, gin.Param{Key: "deliveryId", Value: strconv.FormatInt(delivery.ID, 10)})
+
+			handler, service := setUpWebhookHandler(t)
+			tc.buildStubs(service)
+
+			handler.RedeliverDelivery(ctx)
+			tc.checkResponse(w)
+		})
+	}
+}
+
+func randomSubscription() entity.WebhookSubscriptionViewModel {
+	return entity.WebhookSubscriptionViewModel{
+		ID:         1,
+		URL:        "https://partner.example.com/hooks/orders",
+		EventTypes: []entity.EventType{entity.EventOrderCreated, entity.EventOrderStatusChanged},
+		Secret:     "0123456789abcdef0123456789abcdef",
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func randomDelivery() entity.WebhookDeliveryViewModel {
+	return entity.WebhookDeliveryViewModel{
+		ID:             2,
+		SubscriptionID: 1,
+		EventID:        3,
+		EventType:      entity.EventOrderCreated,
+		Status:         entity.DeliveryDead,
+		Attempts:       8,
+		LastError:      "endpoint responded with 500 Internal Server Error",
+		CreatedAt:      time.Now().UTC().Truncate(time.Second),
+		Log: []entity.WebhookDeliveryAttemptViewModel{
+			{ResponseStatus: http.StatusInternalServerError, Error: "endpoint responded with 500 Internal Server Error"},
+		},
+	}
+}
+
+func setUpWebhookHandler(t *testing.T) (*WebhookHandler, *mockService.MockIWebhookService) {
+	ctrl := gomock.NewController(t)
+
+	webhookService := mockService.NewMockIWebhookService(ctrl)
+	webhookHandler := NewWebhookHandler(webhookService)
+
+	return webhookHandler, webhookService
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Database checks that Postgres answers a ping.
+func Database(db *gorm.DB) Check {
+	return Check{
+		Name: "database",
+		Run: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// Migrations checks that the schema, as recorded by golang-migrate, is at
+// version want and that no migration failed half-way.
+func Migrations(db *gorm.DB, want uint) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			var state struct {
+				Version uint
+				Dirty   bool
+			}
+
+			result := db.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&state)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			switch {
+			case result.RowsAffected == 0:
+				return fmt.Errorf("no migrations applied, want version %d", want)
+			case state.Dirty:
+				return fmt.Errorf("migration %d failed and left the schema dirty", state.Version)
+			case state.Version != want:
+				return fmt.Errorf("schema is at version %d, want %d", state.Version, want)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckerRunsChecksConcurrently(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}
+
+	checker := NewChecker(Check{Name: "a", Run: slow}, Check{Name: "b", Run: slow})
+
+	start := time.Now()
+	report, ok := checker.Ready(context.Background())
+	require.True(t, ok)
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+	require.GreaterOrEqual(t, report.Checks["a"].LatencyMs, float64(50))
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	report, ok := NewChecker(Check{Name: "database", Run: hanging}).Ready(ctx)
+	require.False(t, ok)
+	require.Equal(t, StatusFail, report.Status)
+	require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+}
+
+func TestCheckerShuttingDown(t *testing.T) {
+	ran := false
+	checker := NewChecker(Check{Name: "database", Run: func(ctx context.Context) error {
+		ran = true
+		return errors.New("unreachable")
+	}})
+	checker.ShuttingDown()
+
+	report, ok := checker.Ready(context.Background())
+	require.False(t, ok)
+	require.False(t, ran)
+	require.Equal(t, StatusFail, report.Checks["shutdown"].Status)
+}
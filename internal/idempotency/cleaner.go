@@ -0,0 +1,58 @@
+// Package idempotency keeps the idempotency key store from growing without
+// bound.
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"simple-order-go/internal/repository"
+	"simple-order-go/pkg/config"
+	"time"
+)
+
+const defaultCleanupInterval = 10 * time.Minute
+
+// Cleaner periodically deletes expired idempotency keys.
+type Cleaner struct {
+	idempotencyRepo repository.IIdempotencyRepository
+	interval        time.Duration
+}
+
+func NewCleaner(idempotencyRepo repository.IIdempotencyRepository, cfg config.Idempotency) *Cleaner {
+	c := &Cleaner{
+		idempotencyRepo: idempotencyRepo,
+		interval:        cfg.CleanupInterval,
+	}
+	if c.interval <= 0 {
+		c.interval = defaultCleanupInterval
+	}
+	return c
+}
+
+// Run cleans up every interval until ctx is cancelled.
+func (c *Cleaner) Run(ctx context.Context) {
+	for {
+		if _, err := c.Clean(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "idempotency key cleanup failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.interval):
+		}
+	}
+}
+
+// Clean deletes the keys that have expired and returns how many there were.
+func (c *Cleaner) Clean(ctx context.Context) (int64, error) {
+	deleted, err := c.idempotencyRepo.DeleteExpiredKeys(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if deleted > 0 {
+		slog.DebugContext(ctx, "deleted expired idempotency keys", "count", deleted)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"context"
+	"simple-order-go/internal/entity"
+	"simple-order-go/pkg/config"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryKeys is an IIdempotencyRepository holding keys in memory.
+type memoryKeys struct {
+	mu   sync.Mutex
+	keys []entity.IdempotencyKey
+}
+
+func (m *memoryKeys) CreateKey(ctx context.Context, key entity.IdempotencyKey) (entity.IdempotencyKey, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keys = append(m.keys, key)
+	return key, true, nil
+}
+
+func (m *memoryKeys) CompleteKey(ctx context.Context, owner string, key string, lockToken string, statusCode int, responseBody []byte) error {
+	return nil
+}
+
+func (m *memoryKeys) DeleteKey(ctx context.Context, owner string, key string, lockToken string) error {
+	return nil
+}
+
+func (m *memoryKeys) DeleteExpiredKeys(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var kept []entity.IdempotencyKey
+	for _, key := range m.keys {
+		if key.ExpiresAt.After(time.Now()) {
+			kept = append(kept, key)
+		}
+	}
+
+	deleted := int64(len(m.keys) - len(kept))
+	m.keys = kept
+	return deleted, nil
+}
+
+func (m *memoryKeys) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.keys)
+}
+
+func TestClean(t *testing.T) {
+	keys := &memoryKeys{}
+	_, _, _ = keys.CreateKey(context.Background(), entity.IdempotencyKey{Key: "expired", ExpiresAt: time.Now().Add(-time.Minute)})
+	_, _, _ = keys.CreateKey(context.Background(), entity.IdempotencyKey{Key: "live", ExpiresAt: time.Now().Add(time.Hour)})
+
+	deleted, err := NewCleaner(keys, config.Idempotency{}).Clean(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+	require.Equal(t, 1, keys.count())
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	keys := &memoryKeys{}
+	_, _, _ = keys.CreateKey(context.Background(), entity.IdempotencyKey{Key: "expired", ExpiresAt: time.Now().Add(-time.Minute)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewCleaner(keys, config.Idempotency{CleanupInterval: time.Millisecond}).Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return keys.count() == 0 }, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after cancel")
+	}
+}
@@ -0,0 +1,57 @@
+// Package logging sets up structured JSON logging with log/slog. Records
+// logged with a request's context carry its request ID, caller and trace, so
+// that every line about a request can be found from any one of them.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"simple-order-go/internal/reqctx"
+	"simple-order-go/pkg/config"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New returns a logger writing JSON lines to w at the level cfg names:
+// "debug", "info", "warn" or "error".
+func New(w io.Writer, cfg config.Log) (*slog.Logger, error) {
+	var level slog.Level
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return nil, fmt.Errorf("logging: %w", err)
+		}
+	}
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	return slog.New(contextHandler{handler}), nil
+}
+
+// contextHandler adds what the context knows about the request to every
+// record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := reqctx.RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if actor := reqctx.Actor(ctx); actor != "" {
+		record.AddAttrs(slog.String("actor", actor))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"simple-order-go/internal/reqctx"
+	"simple-order-go/pkg/config"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, config.Log{Level: "warn"})
+	require.NoError(t, err)
+
+	ctx := reqctx.WithRequestID(context.Background(), "req-1")
+	ctx = reqctx.WithActor(ctx, "customer-1")
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(ctx, "request")
+	defer span.End()
+
+	logger.InfoContext(ctx, "below the level")
+	logger.With("component", "test").WarnContext(ctx, "something odd")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "something odd", record["msg"])
+	require.Equal(t, "test", record["component"])
+	require.Equal(t, "req-1", record["request_id"])
+	require.Equal(t, "customer-1", record["actor"])
+	require.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	require.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+}
+
+func TestNewLevel(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, config.Log{})
+	require.NoError(t, err)
+
+	_, err = New(&bytes.Buffer{}, config.Log{Level: "verbose"})
+	require.Error(t, err)
+}
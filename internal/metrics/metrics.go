@@ -0,0 +1,65 @@
+// Package metrics defines the Prometheus metrics of the API and the business
+// it serves. They are registered with the default registry, which /metrics
+// exposes.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "simple_order"
+
+// DeleteKind tells soft deletes apart from the purges that remove orders for
+// good.
+type DeleteKind string
+
+const (
+	DeleteSoft  DeleteKind = "soft"
+	DeletePurge DeleteKind = "purge"
+)
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	ordersCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Orders created.",
+	})
+
+	itemsPerOrder = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "order_items",
+		Help:      "Number of items orders are created with.",
+		Buckets:   []float64{0, 1, 2, 3, 5, 10, 20, 50, 100},
+	})
+
+	ordersDeleted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_deleted_total",
+		Help:      "Orders deleted, by kind of delete.",
+	}, []string{"kind"})
+)
+
+// OrderCreated counts a created order with the given number of items.
+func OrderCreated(items int) {
+	ordersCreated.Inc()
+	itemsPerOrder.Observe(float64(items))
+}
+
+// OrdersDeleted counts n deleted orders.
+func OrdersDeleted(kind DeleteKind, n int64) {
+	ordersDeleted.WithLabelValues(string(kind)).Add(float64(n))
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderCreated(t *testing.T) {
+	before := testutil.ToFloat64(ordersCreated)
+
+	OrderCreated(3)
+	OrderCreated(0)
+
+	require.Equal(t, before+2, testutil.ToFloat64(ordersCreated))
+	require.Equal(t, 1, testutil.CollectAndCount(itemsPerOrder))
+}
+
+func TestOrdersDeleted(t *testing.T) {
+	soft := ordersDeleted.WithLabelValues(string(DeleteSoft))
+	purge := ordersDeleted.WithLabelValues(string(DeletePurge))
+	softBefore, purgeBefore := testutil.ToFloat64(soft), testutil.ToFloat64(purge)
+
+	OrdersDeleted(DeleteSoft, 1)
+	OrdersDeleted(DeletePurge, 25)
+
+	require.Equal(t, softBefore+1, testutil.ToFloat64(soft))
+	require.Equal(t, purgeBefore+25, testutil.ToFloat64(purge))
+}
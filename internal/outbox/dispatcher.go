@@ -0,0 +1,141 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/pkg/config"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultClaimLease   = 2 * time.Minute
+
+	// leaseMargin is kept free at the end of a lease for marking the last
+	// event published.
+	leaseMargin = 10 * time.Second
+
+	minRetryDelay = time.Second
+	maxRetryDelay = 5 * time.Minute
+)
+
+// Dispatcher moves events from the outbox to a Publisher. An event is only
+// marked published after the publisher accepted it, so delivery is at least
+// once. Events are published in the order they were written, but a failed
+// event is retried with exponential backoff while later events go ahead.
+type Dispatcher struct {
+	outboxRepo   repository.IOutboxRepository
+	publisher    Publisher
+	pollInterval time.Duration
+	batchSize    int
+	timeout      time.Duration
+	lease        time.Duration
+}
+
+func NewDispatcher(outboxRepo repository.IOutboxRepository, publisher Publisher, cfg config.Outbox) *Dispatcher {
+	d := &Dispatcher{
+		outboxRepo:   outboxRepo,
+		publisher:    publisher,
+		pollInterval: cfg.PollInterval,
+		batchSize:    cfg.BatchSize,
+	}
+	if d.pollInterval <= 0 {
+		d.pollInterval = defaultPollInterval
+	}
+	if d.batchSize <= 0 {
+		d.batchSize = defaultBatchSize
+	}
+
+	d.timeout = cfg.WebhookTimeout
+	if d.timeout <= 0 {
+		d.timeout = defaultWebhookTimeout
+	}
+	maxLease := cfg.ClaimLease
+	if maxLease <= 0 {
+		maxLease = defaultClaimLease
+	}
+	d.lease = claimLease(d.batchSize, d.timeout, maxLease)
+	return d
+}
+
+// claimLease is how long a claimed batch is reserved: long enough for every
+// publish in it to time out, but no longer than maxLease, since that is also
+// how long the events of a dispatcher that died wait to be claimed again.
+func claimLease(batchSize int, timeout time.Duration, maxLease time.Duration) time.Duration {
+	lease := time.Duration(batchSize)*timeout + leaseMargin
+	if lease > maxLease {
+		lease = maxLease
+	}
+	if lease < timeout+leaseMargin {
+		lease = timeout + leaseMargin
+	}
+	return lease
+}
+
+// Run dispatches events until ctx is cancelled. A full batch is followed by
+// the next one right away; otherwise Run waits for the poll interval.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		n, err := d.Dispatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "outbox dispatch failed", "error", err)
+		}
+
+		if n == d.batchSize && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.pollInterval):
+		}
+	}
+}
+
+// Dispatch publishes one batch of due events and returns how many it
+// claimed.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	events, err := d.outboxRepo.ClaimEvents(ctx, d.batchSize, d.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	// Events the lease can no longer cover are left to be claimed again once
+	// it runs out. It always covers the first.
+	deadline := time.Now().Add(d.lease - d.timeout - leaseMargin)
+	for i, event := range events {
+		if i > 0 && time.Now().After(deadline) {
+			break
+		}
+
+		if err := d.publish(ctx, event); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
+
+func (d *Dispatcher) publish(ctx context.Context, event entity.OutboxEvent) error {
+	if err := d.publisher.Publish(ctx, event.ToEvent()); err != nil {
+		return d.outboxRepo.MarkFailed(ctx, event.ID, err.Error(), time.Now().Add(retryDelay(event.Attempts)))
+	}
+
+	return d.outboxRepo.MarkPublished(ctx, event.ID)
+}
+
+// retryDelay is the backoff after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,186 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"simple-order-go/internal/entity"
+	"simple-order-go/pkg/config"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutbox is an IOutboxRepository holding events in memory.
+type memoryOutbox struct {
+	mu     sync.Mutex
+	events []entity.OutboxEvent
+}
+
+func (o *memoryOutbox) add(eventType entity.EventType) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.events = append(o.events, entity.OutboxEvent{
+		ID:      int64(len(o.events) + 1),
+		OrderID: 1,
+		Type:    eventType,
+		Payload: json.RawMessage(`{}`),
+	})
+}
+
+func (o *memoryOutbox) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var claimed []entity.OutboxEvent
+	for i := range o.events {
+		event := &o.events[i]
+		if event.PublishedAt != nil || event.AvailableAt.After(time.Now()) || len(claimed) == limit {
+			continue
+		}
+
+		event.Attempts++
+		event.AvailableAt = time.Now().Add(lease)
+		claimed = append(claimed, *event)
+	}
+	return claimed, nil
+}
+
+func (o *memoryOutbox) MarkPublished(ctx context.Context, eventID int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	o.events[eventID-1].PublishedAt = &now
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(ctx context.Context, eventID int64, reason string, retryAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.events[eventID-1].LastError = reason
+	o.events[eventID-1].AvailableAt = retryAt
+	return nil
+}
+
+// flakyPublisher fails the first attempt at publishing each event.
+type flakyPublisher struct {
+	MemoryPublisher
+	failed map[int64]bool
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, event entity.Event) error {
+	if !p.failed[event.ID] {
+		p.failed[event.ID] = true
+		return errors.New("unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, event)
+}
+
+func TestDispatch(t *testing.T) {
+	outbox := &memoryOutbox{}
+	outbox.add(entity.EventOrderCreated)
+	outbox.add(entity.EventItemAdded)
+
+	publisher := NewMemoryPublisher()
+	dispatcher := NewDispatcher(outbox, publisher, config.Outbox{})
+
+	n, err := dispatcher.Dispatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	events := publisher.Events()
+	require.Equal(t, 2, len(events))
+	require.Equal(t, entity.EventOrderCreated, events[0].Type)
+	require.Equal(t, entity.EventItemAdded, events[1].Type)
+
+	n, err = dispatcher.Dispatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+}
+
+func TestDispatchRetriesFailedEvents(t *testing.T) {
+	outbox := &memoryOutbox{}
+	outbox.add(entity.EventOrderCreated)
+
+	publisher := &flakyPublisher{failed: map[int64]bool{}}
+	dispatcher := NewDispatcher(outbox, publisher, config.Outbox{})
+
+	_, err := dispatcher.Dispatch(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, publisher.Events())
+	require.Equal(t, "unavailable", outbox.events[0].LastError)
+	require.WithinDuration(t, time.Now().Add(minRetryDelay), outbox.events[0].AvailableAt, 100*time.Millisecond)
+
+	// Not due yet.
+	n, err := dispatcher.Dispatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	outbox.events[0].AvailableAt = time.Now()
+	_, err = dispatcher.Dispatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(publisher.Events()))
+	require.NotNil(t, outbox.events[0].PublishedAt)
+}
+
+func TestRunStopsWithContext(t *testing.T) {
+	outbox := &memoryOutbox{}
+	outbox.add(entity.EventOrderCreated)
+
+	publisher := NewMemoryPublisher()
+	dispatcher := NewDispatcher(outbox, publisher, config.Outbox{PollInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return len(publisher.Events()) == 1 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after the context was cancelled")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, time.Second, retryDelay(1))
+	require.Equal(t, 2*time.Second, retryDelay(2))
+	require.Equal(t, 8*time.Second, retryDelay(4))
+	require.Equal(t, maxRetryDelay, retryDelay(50))
+}
+
+func TestClaimLease(t *testing.T) {
+	// Every publish in a batch of 10 may take the whole 5s timeout.
+	require.Equal(t, 10*5*time.Second+leaseMargin, claimLease(10, 5*time.Second, time.Hour))
+	require.Equal(t, 2*time.Minute, claimLease(100, 5*time.Second, 2*time.Minute))
+	// A lease always covers at least one publish.
+	require.Equal(t, 5*time.Second+leaseMargin, claimLease(100, 5*time.Second, time.Second))
+}
+
+func TestDispatchStopsWhenLeaseRunsOut(t *testing.T) {
+	outboxRepo := &memoryOutbox{}
+	outboxRepo.add(entity.EventOrderCreated)
+	outboxRepo.add(entity.EventOrderUpdated)
+
+	publisher := NewMemoryPublisher()
+	dispatcher := NewDispatcher(outboxRepo, publisher, config.Outbox{WebhookTimeout: time.Second})
+	// A lease too short for more than one publish leaves the rest of the
+	// batch.
+	dispatcher.lease = dispatcher.timeout
+
+	n, err := dispatcher.Dispatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Len(t, publisher.Events(), 1)
+	require.Equal(t, entity.EventOrderCreated, publisher.Events()[0].Type)
+}
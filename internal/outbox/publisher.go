@@ -0,0 +1,147 @@
+// Package outbox publishes the domain events that repositories write to the
+// outbox table.
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"simple-order-go/internal/entity"
+	"simple-order-go/pkg/config"
+	"sync"
+	"time"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// Publisher delivers events to their consumers. An error means the event
+// wasn't delivered and will be retried, so an event may be published more
+// than once.
+type Publisher interface {
+	Publish(ctx context.Context, event entity.Event) error
+}
+
+// NewPublisher returns the publisher selected by cfg.Publisher.
+func NewPublisher(cfg config.Outbox) (Publisher, error) {
+	switch cfg.Publisher {
+	case "", "log":
+		return NewLogPublisher(slog.Default()), nil
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("outbox: webhook publisher needs a webhook_url")
+		}
+		return NewWebhookPublisher(cfg.WebhookURL, cfg.WebhookTimeout), nil
+	case "memory":
+		return NewMemoryPublisher(), nil
+	}
+	return nil, fmt.Errorf("outbox: unknown publisher %q", cfg.Publisher)
+}
+
+// LogPublisher writes events to a logger.
+type LogPublisher struct {
+	logger *slog.Logger
+}
+
+func NewLogPublisher(logger *slog.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event entity.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.logger.InfoContext(ctx, "outbox event", "event", json.RawMessage(data))
+	return nil
+}
+
+// WebhookPublisher POSTs each event as JSON to a URL. Any response other than
+// 2xx counts as a failure.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookPublisher(url string, timeout time.Duration) *WebhookPublisher {
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookPublisher{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event entity.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+// MultiPublisher publishes each event to several publishers. If any of them
+// fails the event is retried on all of them, so each must cope with
+// duplicates.
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+func (p *MultiPublisher) Publish(ctx context.Context, event entity.Event) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MemoryPublisher keeps published events in memory, for tests.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []entity.Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event entity.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events returns the events published so far.
+func (p *MemoryPublisher) Events() []entity.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]entity.Event(nil), p.events...)
+}
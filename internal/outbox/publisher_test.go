@@ -0,0 +1,110 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/entity"
+	"simple-order-go/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomEvent() entity.Event {
+	return entity.Event{
+		ID:         1,
+		Type:       entity.EventOrderCreated,
+		OrderID:    2,
+		Data:       json.RawMessage(`{"id":2}`),
+		OccurredAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	event := randomEvent()
+
+	testCases := []struct {
+		name       string
+		status     int
+		checkError func(err error)
+	}{
+		{
+			name:   "OK",
+			status: http.StatusNoContent,
+			checkError: func(err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "ServerError",
+			status: http.StatusInternalServerError,
+			checkError: func(err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var got entity.Event
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.NoError(t, json.Unmarshal(body, &got))
+
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			publisher := NewWebhookPublisher(server.URL, time.Second)
+			tc.checkError(publisher.Publish(context.Background(), event))
+			require.Equal(t, event.ID, got.ID)
+			require.Equal(t, event.Type, got.Type)
+			require.JSONEq(t, string(event.Data), string(got.Data))
+		})
+	}
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(ctx context.Context, event entity.Event) error {
+	return errors.New("unavailable")
+}
+
+func TestMultiPublisher(t *testing.T) {
+	event := randomEvent()
+	first, second := NewMemoryPublisher(), NewMemoryPublisher()
+
+	err := NewMultiPublisher(first, second).Publish(context.Background(), event)
+	require.NoError(t, err)
+	require.Equal(t, []entity.Event{event}, first.Events())
+	require.Equal(t, []entity.Event{event}, second.Events())
+
+	// A failure doesn't keep the others from getting the event.
+	third := NewMemoryPublisher()
+	err = NewMultiPublisher(failingPublisher{}, third).Publish(context.Background(), event)
+	require.Error(t, err)
+	require.Equal(t, []entity.Event{event}, third.Events())
+}
+
+func TestNewPublisher(t *testing.T) {
+	publisher, err := NewPublisher(config.Outbox{Publisher: "memory"})
+	require.NoError(t, err)
+	require.IsType(t, &MemoryPublisher{}, publisher)
+
+	_, err = NewPublisher(config.Outbox{Publisher: "webhook"})
+	require.Error(t, err)
+
+	_, err = NewPublisher(config.Outbox{Publisher: "kafka"})
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often MemoryLimiter forgets buckets that are full,
+// which behave the same as missing ones.
+const sweepInterval = time.Minute
+
+// bucket stores the time at which it will be full again instead of a token
+// count, so that it needs no refilling.
+type bucket struct {
+	fullAt time.Time
+}
+
+// MemoryLimiter keeps buckets in process memory. Every instance of the API
+// enforces its own limits.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	capacity := limit.capacity()
+	if limit.IsZero() {
+		return Result{Allowed: true, Limit: capacity, Remaining: capacity}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok || b.fullAt.Before(now) {
+		b = &bucket{fullAt: now}
+		l.buckets[key] = b
+	}
+
+	interval := limit.interval()
+	window := interval * time.Duration(capacity)
+
+	// The bucket is empty once it is a whole window away from being full.
+	fullAt := b.fullAt.Add(interval)
+	if fullAt.Sub(now) > window {
+		return Result{
+			Limit:      capacity,
+			Reset:      b.fullAt.Sub(now),
+			RetryAfter: fullAt.Sub(now) - window,
+		}, nil
+	}
+
+	b.fullAt = fullAt
+	return Result{
+		Allowed:   true,
+		Limit:     capacity,
+		Remaining: int((window - fullAt.Sub(now)) / interval),
+		Reset:     fullAt.Sub(now),
+	}, nil
+}
+
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if !b.fullAt.After(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter() (*MemoryLimiter, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestMemoryLimiterBurst(t *testing.T) {
+	limiter, now := newTestLimiter()
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 3}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := limiter.Allow(context.Background(), "client", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 3, result.Limit)
+		require.Equal(t, remaining, result.Remaining)
+	}
+
+	result, err := limiter.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Zero(t, result.Remaining)
+	require.Equal(t, time.Second, result.RetryAfter)
+	require.Equal(t, 3*time.Second, result.Reset)
+
+	// Other clients have buckets of their own.
+	result, err = limiter.Allow(context.Background(), "other", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	*now = now.Add(time.Second)
+	result, err = limiter.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Zero(t, result.Remaining)
+}
+
+func TestMemoryLimiterRefill(t *testing.T) {
+	limiter, now := newTestLimiter()
+	limit := Limit{Requests: 2, Period: time.Second}
+
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow(context.Background(), "client", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+
+	*now = now.Add(time.Hour)
+	result, err := limiter.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 1, result.Remaining)
+	require.Equal(t, 1, len(limiter.buckets))
+}
+
+func TestMemoryLimiterZeroLimit(t *testing.T) {
+	limiter, _ := newTestLimiter()
+
+	for i := 0; i < 10; i++ {
+		result, err := limiter.Allow(context.Background(), "client", Limit{})
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+	require.Empty(t, limiter.buckets)
+}
@@ -0,0 +1,55 @@
+// Package ratelimit limits how often clients may call the API using token
+// buckets. Each bucket holds up to Burst tokens and refills at Requests per
+// Period; a request takes one token and is refused when there is none.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit is the allowance of a single bucket.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	// Burst is the size of the bucket. Zero means Requests.
+	Burst int
+}
+
+// capacity returns the number of tokens a full bucket holds.
+func (l Limit) capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// interval returns the time it takes to refill one token.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// IsZero reports whether l doesn't limit anything.
+func (l Limit) IsZero() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// Result is the state of a bucket after a request took, or failed to take,
+// a token from it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request would be allowed. It is
+	// zero for allowed requests.
+	RetryAfter time.Duration
+}
+
+// Limiter takes tokens from the bucket stored under key, creating it full if
+// there is none. Implementations backed by a shared store let several
+// instances of the API enforce a common limit.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
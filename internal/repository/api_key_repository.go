@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPIKeyNotFound = apperror.NotFound("api_key_not_found", "api key not found")
+	ErrAPIKeyRevoked  = apperror.Conflict("api_key_revoked", "api key is revoked")
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// IAPIKeyRepository stores API keys by the hash of the key. Revoked keys are
+// kept, but never match GetKeyByHash again.
+type IAPIKeyRepository interface {
+	CreateKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error)
+	GetKeys(ctx context.Context) ([]entity.APIKey, error)
+	GetKeyByHash(ctx context.Context, hash string) (entity.APIKey, error)
+	RotateKey(ctx context.Context, keyID int64, prefix string, hash string) (entity.APIKey, error)
+	RevokeKey(ctx context.Context, keyID int64) error
+	TouchKey(ctx context.Context, keyID int64, usedAt time.Time, precision time.Duration) error
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) CreateKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	err := conn(ctx, r.db).Create(&key).Error
+	return key, err
+}
+
+func (r *APIKeyRepository) GetKeys(ctx context.Context) ([]entity.APIKey, error) {
+	var keys []entity.APIKey
+	err := conn(ctx, r.db).Order("id").Find(&keys).Error
+	return keys, err
+}
+
+// GetKeyByHash returns the active key with the given hash.
+func (r *APIKeyRepository) GetKeyByHash(ctx context.Context, hash string) (key entity.APIKey, err error) {
+	err = conn(ctx, r.db).Take(&key, "hash = ? AND revoked_at IS NULL", hash).Error
+	err = apiKeyNotFound(err)
+	return
+}
+
+// RotateKey replaces the secret of an active key, which stops the old one
+// from working right away. It returns ErrAPIKeyRevoked for a revoked key.
+func (r *APIKeyRepository) RotateKey(ctx context.Context, keyID int64, prefix string, hash string) (entity.APIKey, error) {
+	var key entity.APIKey
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.APIKey{}).Where("id = ? AND revoked_at IS NULL", keyID).
+			Updates(map[string]interface{}{"prefix": prefix, "hash": hash, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		err := apiKeyNotFound(tx.Take(&key, "id = ?", keyID).Error)
+		if err != nil {
+			return err
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrAPIKeyRevoked
+		}
+		return nil
+	})
+
+	return key, err
+}
+
+// RevokeKey revokes a key. Revoking a revoked key does nothing.
+func (r *APIKeyRepository) RevokeKey(ctx context.Context, keyID int64) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entity.APIKey{}).Where("id = ? AND revoked_at IS NULL", keyID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+
+		return apiKeyNotFound(tx.Select("id").Take(&entity.APIKey{}, "id = ?", keyID).Error)
+	})
+}
+
+// TouchKey records that a key was used at usedAt. To spare a write on every
+// request, the stored time is only moved if it is more than precision older.
+func (r *APIKeyRepository) TouchKey(ctx context.Context, keyID int64, usedAt time.Time, precision time.Duration) error {
+	return conn(ctx, r.db).Model(&entity.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, usedAt.Add(-precision)).
+		Update("last_used_at", usedAt).Error
+}
+
+func apiKeyNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"simple-order-go/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateAPIKey(t *testing.T) {
+	defer tearDown()
+
+	key := createAPIKey(t, "hash-1")
+
+	rotated, err := testAPIKeyRepo.RotateKey(context.Background(), key.ID, "sok_rotated1", "hash-2")
+	require.NoError(t, err)
+	require.Equal(t, key.Name, rotated.Name)
+	require.Equal(t, key.Scopes, rotated.Scopes)
+	require.Equal(t, "hash-2", rotated.Hash)
+
+	_, err = testAPIKeyRepo.GetKeyByHash(context.Background(), "hash-1")
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+	got, err := testAPIKeyRepo.GetKeyByHash(context.Background(), "hash-2")
+	require.NoError(t, err)
+	require.Equal(t, key.ID, got.ID)
+
+	_, err = testAPIKeyRepo.RotateKey(context.Background(), key.ID+1, "sok_rotated2", "hash-3")
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	defer tearDown()
+
+	key := createAPIKey(t, "hash-1")
+
+	err := testAPIKeyRepo.RevokeKey(context.Background(), key.ID)
+	require.NoError(t, err)
+
+	// Revoking twice is fine.
+	err = testAPIKeyRepo.RevokeKey(context.Background(), key.ID)
+	require.NoError(t, err)
+
+	_, err = testAPIKeyRepo.GetKeyByHash(context.Background(), key.Hash)
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+	_, err = testAPIKeyRepo.RotateKey(context.Background(), key.ID, "sok_rotated1", "hash-2")
+	require.ErrorIs(t, err, ErrAPIKeyRevoked)
+
+	keys, err := testAPIKeyRepo.GetKeys(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(keys))
+	require.NotNil(t, keys[0].RevokedAt)
+
+	err = testAPIKeyRepo.RevokeKey(context.Background(), key.ID+1)
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func TestTouchAPIKey(t *testing.T) {
+	defer tearDown()
+
+	key := createAPIKey(t, "hash-1")
+	usedAt := time.Now().UTC().Truncate(time.Second)
+
+	err := testAPIKeyRepo.TouchKey(context.Background(), key.ID, usedAt, time.Minute)
+	require.NoError(t, err)
+
+	// Uses within the precision don't move the time.
+	err = testAPIKeyRepo.TouchKey(context.Background(), key.ID, usedAt.Add(30*time.Second), time.Minute)
+	require.NoError(t, err)
+
+	got, err := testAPIKeyRepo.GetKeyByHash(context.Background(), key.Hash)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastUsedAt)
+	require.WithinDuration(t, usedAt, *got.LastUsedAt, time.Second)
+
+	err = testAPIKeyRepo.TouchKey(context.Background(), key.ID, usedAt.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+
+	got, err = testAPIKeyRepo.GetKeyByHash(context.Background(), key.Hash)
+	require.NoError(t, err)
+	require.WithinDuration(t, usedAt.Add(2*time.Minute), *got.LastUsedAt, time.Second)
+}
+
+func createAPIKey(t *testing.T, hash string) entity.APIKey {
+	key, err := testAPIKeyRepo.CreateKey(context.Background(), entity.APIKey{
+		Name:      "nightly export",
+		Prefix:    "sok_Ab3dEfGh",
+		Hash:      hash,
+		Scopes:    []entity.Scope{entity.ScopeOrdersRead},
+		CreatedBy: "admin-1",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, key.ID)
+	return key
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"simple-order-go/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// IAuditRepository stores the audit trail of orders. It only ever appends.
+type IAuditRepository interface {
+	CreateEntry(ctx context.Context, entry entity.AuditEntry) error
+	GetEntries(ctx context.Context, orderID int64) ([]entity.AuditEntry, error)
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) CreateEntry(ctx context.Context, entry entity.AuditEntry) error {
+	return conn(ctx, r.db).Create(&entry).Error
+}
+
+func (r *AuditRepository) GetEntries(ctx context.Context, orderID int64) ([]entity.AuditEntry, error) {
+	var entries []entity.AuditEntry
+	err := conn(ctx, r.db).Where("order_id = ?", orderID).Order("created_at, id").Find(&entries).Error
+	return entries, err
+}
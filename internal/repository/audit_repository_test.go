@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"simple-order-go/internal/entity"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createAuditEntry(t *testing.T, ctx context.Context, orderID int64, action entity.AuditAction) {
+	changes, err := json.Marshal([]entity.AuditChange{{Path: "customer_name", From: "a", To: "b"}})
+	require.NoError(t, err)
+
+	err = testAuditRepo.CreateEntry(ctx, entity.AuditEntry{
+		OrderID:   orderID,
+		Action:    action,
+		Actor:     "anonymous",
+		RequestID: "req-1",
+		Changes:   changes,
+	})
+	require.NoError(t, err)
+}
+
+func TestGetAuditEntries(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	createAuditEntry(t, context.Background(), order.ID, entity.AuditActionCreated)
+	createAuditEntry(t, context.Background(), order.ID, entity.AuditActionUpdated)
+	createAuditEntry(t, context.Background(), order.ID+1, entity.AuditActionCreated)
+
+	entries, err := testAuditRepo.GetEntries(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	require.Equal(t, entity.AuditActionCreated, entries[0].Action)
+	require.Equal(t, entity.AuditActionUpdated, entries[1].Action)
+	require.Equal(t, "req-1", entries[0].RequestID)
+	require.JSONEq(t, `[{"path":"customer_name","from":"a","to":"b"}]`, string(entries[0].Changes))
+}
+
+// An entry written in a transaction that fails is rolled back together with
+// the change it describes.
+func TestAuditEntryRollsBackWithTransaction(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	errAbort := errors.New("abort")
+
+	err := testTransactor.Transaction(context.Background(), func(ctx context.Context) error {
+		err := testOrderRepo.DeleteOrder(ctx, order.ID, order.Version)
+		require.NoError(t, err)
+
+		createAuditEntry(t, ctx, order.ID, entity.AuditActionDeleted)
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	_, err = testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+
+	entries, err := testAuditRepo.GetEntries(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
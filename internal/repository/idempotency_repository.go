@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrIdempotencyLockLost = apperror.Conflict("idempotency_lock_lost", "idempotency key was taken over by another request")
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+type IIdempotencyRepository interface {
+	CreateKey(ctx context.Context, key entity.IdempotencyKey) (entity.IdempotencyKey, bool, error)
+	CompleteKey(ctx context.Context, owner string, key string, lockToken string, statusCode int, responseBody []byte) error
+	DeleteKey(ctx context.Context, owner string, key string, lockToken string) error
+	DeleteExpiredKeys(ctx context.Context) (int64, error)
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// CreateKey stores a new key. If its owner already has the key it returns the
+// stored record instead and reports false. An existing key is taken over, as
+// if it were new, once it has expired, or if it is still in progress for the
+// same request but its lock has run out because that request died.
+func (r *IdempotencyRepository) CreateKey(ctx context.Context, key entity.IdempotencyKey) (entity.IdempotencyKey, bool, error) {
+	now := time.Now()
+	result := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "owner"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"fingerprint", "status_code", "response_body", "locked_until", "lock_token", "expires_at", "created_at", "updated_at",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Or(
+			clause.Lte{Column: "idempotency_keys.expires_at", Value: now},
+			clause.And(
+				clause.Eq{Column: "idempotency_keys.status_code", Value: 0},
+				clause.Lte{Column: "idempotency_keys.locked_until", Value: now},
+				clause.Expr{SQL: "idempotency_keys.fingerprint = excluded.fingerprint"},
+			),
+		)}},
+	}).Create(&key)
+	if result.Error != nil {
+		return entity.IdempotencyKey{}, false, result.Error
+	}
+
+	if result.RowsAffected == 1 {
+		return key, true, nil
+	}
+
+	var existing entity.IdempotencyKey
+	err := conn(ctx, r.db).Take(&existing, "owner = ? AND key = ?", key.Owner, key.Key).Error
+	return existing, false, err
+}
+
+// CompleteKey stores the response to the request holding lockToken. It
+// returns ErrIdempotencyLockLost if another request has taken the key over.
+func (r *IdempotencyRepository) CompleteKey(ctx context.Context, owner string, key string, lockToken string, statusCode int, responseBody []byte) error {
+	result := conn(ctx, r.db).Model(&entity.IdempotencyKey{}).
+		Where("owner = ? AND key = ? AND lock_token = ? AND status_code = 0", owner, key, lockToken).
+		Updates(entity.IdempotencyKey{
+			StatusCode:   statusCode,
+			ResponseBody: responseBody,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrIdempotencyLockLost
+	}
+	return nil
+}
+
+// DeleteKey forgets a key still in progress for the request holding
+// lockToken. It returns ErrIdempotencyLockLost if another request has taken
+// the key over.
+func (r *IdempotencyRepository) DeleteKey(ctx context.Context, owner string, key string, lockToken string) error {
+	result := conn(ctx, r.db).Delete(&entity.IdempotencyKey{},
+		"owner = ? AND key = ? AND lock_token = ? AND status_code = 0", owner, key, lockToken)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrIdempotencyLockLost
+	}
+	return nil
+}
+
+// DeleteExpiredKeys removes the keys that have expired and returns how many
+// there were.
+func (r *IdempotencyRepository) DeleteExpiredKeys(ctx context.Context) (int64, error) {
+	result := conn(ctx, r.db).Delete(&entity.IdempotencyKey{}, "expires_at <= ?", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"simple-order-go/common"
+	"simple-order-go/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomIdempotencyKey() entity.IdempotencyKey {
+	return entity.IdempotencyKey{
+		Owner:       common.RandomName(),
+		Key:         common.RandomString(16),
+		Fingerprint: common.RandomString(64),
+		LockedUntil: time.Now().Add(time.Minute),
+		LockToken:   common.RandomString(32),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	arg := randomIdempotencyKey()
+
+	key, created, err := testIdempotencyRepo.CreateKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, arg.Key, key.Key)
+	require.False(t, key.Completed())
+
+	other := arg
+	other.Fingerprint = common.RandomString(64)
+	existing, created, err := testIdempotencyRepo.CreateKey(context.Background(), other)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, arg.Fingerprint, existing.Fingerprint)
+}
+
+func TestCompleteIdempotencyKey(t *testing.T) {
+	arg := randomIdempotencyKey()
+
+	_, _, err := testIdempotencyRepo.CreateKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	body := []byte(`{"id":1}`)
+	err = testIdempotencyRepo.CompleteKey(context.Background(), arg.Owner, arg.Key, arg.LockToken, http.StatusOK, body)
+	require.NoError(t, err)
+
+	key, created, err := testIdempotencyRepo.CreateKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.True(t, key.Completed())
+	require.Equal(t, http.StatusOK, key.StatusCode)
+	require.Equal(t, body, key.ResponseBody)
+}
+
+func TestDeleteIdempotencyKey(t *testing.T) {
+	arg := randomIdempotencyKey()
+
+	_, _, err := testIdempotencyRepo.CreateKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	err = testIdempotencyRepo.DeleteKey(context.Background(), arg.Owner, arg.Key, arg.LockToken)
+	require.NoError(t, err)
+
+	_, created, err := testIdempotencyRepo.CreateKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, created)
+}
+
+func TestIdempotencyKeyOwners(t *testing.T) {
+	arg := randomIdempotencyKey()
+
+	_, _, err := testIdempotencyRepo.CreateKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	err = testIdempotencyRepo.CompleteKey(context.Background(), arg.Owner, arg.Key, arg.LockToken, http.StatusCreated, []byte(`{"id":1}`))
+	require.NoError(t, err)
+
+	other := arg
+	other.Owner = arg.Owner + "-other"
+	key, created, err := testIdempotencyRepo.CreateKey(context.Background(), other)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.False(t, key.Completed())
+}
+
+func TestTakeOverStaleIdempotencyKey(t *testing.T) {
+	arg := randomIdempotencyKey()
+	arg.LockedUntil = time.Now().Add(-time.Second)
+
+	_, _, err := testIdempotencyRepo.CreateKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	// A different request can't take over the key, even though its lock ran
+	// out.
+	other := randomIdempotencyKey()
+	other.Owner, other.Key = arg.Owner, arg.Key
+	existing, created, err := testIdempotencyRepo.CreateKey(context.Background(), other)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, arg.Fingerprint, existing.Fingerprint)
+
+	retry := arg
+	retry.LockedUntil = time.Now().Add(time.Minute)
+	retry.LockToken = common.RandomString(32)
+	_, created, err = testIdempotencyRepo.CreateKey(context.Background(), retry)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	// Now that the retry holds the lock, the key is in progress again.
+	_, created, err = testIdempotencyRepo.CreateKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, created)
+
+	// The request that lost the lock can neither release the key nor store
+	// its response over the retry's.
+	err = testIdempotencyRepo.DeleteKey(context.Background(), arg.Owner, arg.Key, arg.LockToken)
+	require.ErrorIs(t, err, ErrIdempotencyLockLost)
+	err = testIdempotencyRepo.CompleteKey(context.Background(), arg.Owner, arg.Key, arg.LockToken, http.StatusCreated, []byte(`{"id":1}`))
+	require.ErrorIs(t, err, ErrIdempotencyLockLost)
+
+	err = testIdempotencyRepo.CompleteKey(context.Background(), retry.Owner, retry.Key, retry.LockToken, http.StatusCreated, []byte(`{"id":2}`))
+	require.NoError(t, err)
+
+	key, _, err := testIdempotencyRepo.CreateKey(context.Background(), retry)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"id":2}`), key.ResponseBody)
+
+	// A completed key can't be released any more.
+	err = testIdempotencyRepo.DeleteKey(context.Background(), retry.Owner, retry.Key, retry.LockToken)
+	require.ErrorIs(t, err, ErrIdempotencyLockLost)
+}
+
+func TestTakeOverExpiredIdempotencyKey(t *testing.T) {
+	arg := randomIdempotencyKey()
+	arg.ExpiresAt = time.Now().Add(-time.Second)
+
+	_, _, err := testIdempotencyRepo.CreateKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	err = testIdempotencyRepo.CompleteKey(context.Background(), arg.Owner, arg.Key, arg.LockToken, http.StatusCreated, []byte(`{"id":1}`))
+	require.NoError(t, err)
+
+	other := randomIdempotencyKey()
+	other.Owner, other.Key = arg.Owner, arg.Key
+	key, created, err := testIdempotencyRepo.CreateKey(context.Background(), other)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, other.Fingerprint, key.Fingerprint)
+	require.False(t, key.Completed())
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	expired := randomIdempotencyKey()
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	live := randomIdempotencyKey()
+
+	for _, key := range []entity.IdempotencyKey{expired, live} {
+		_, _, err := testIdempotencyRepo.CreateKey(context.Background(), key)
+		require.NoError(t, err)
+	}
+
+	deleted, err := testIdempotencyRepo.DeleteExpiredKeys(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	for _, tc := range []struct {
+		key   entity.IdempotencyKey
+		count int64
+	}{{expired, 0}, {live, 1}} {
+		var count int64
+		err = testDB.Model(&entity.IdempotencyKey{}).Where("owner = ? AND key = ?", tc.key.Owner, tc.key.Key).Count(&count).Error
+		require.NoError(t, err)
+		require.Equal(t, tc.count, count)
+	}
+}
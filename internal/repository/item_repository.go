@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"simple-order-go/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type ItemRepository struct {
+	db *gorm.DB
+}
+
+// IItemRepository manages the items of an order. Every change also stores
+// the order's recomputed totals and bumps its version, failing with
+// ErrVersionMismatch if the order moved since it was read, ErrOrderNotFound if
+// it is gone, and ErrItemNotFound if the item isn't part of the order.
+type IItemRepository interface {
+	CreateItem(ctx context.Context, order entity.Order, item entity.Item) (entity.Item, error)
+	UpdateItem(ctx context.Context, order entity.Order, item entity.Item) (entity.Item, error)
+	DeleteItem(ctx context.Context, order entity.Order, itemID int64) error
+}
+
+func NewItemRepository(db *gorm.DB) *ItemRepository {
+	return &ItemRepository{db: db}
+}
+
+func (r *ItemRepository) CreateItem(ctx context.Context, order entity.Order, item entity.Item) (entity.Item, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := saveOrderTotals(tx, order); err != nil {
+			return err
+		}
+
+		item.OrderID = order.ID
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+
+		return enqueueEvent(tx, order.ID, entity.EventItemAdded, item.ToViewModel())
+	})
+
+	return item, err
+}
+
+func (r *ItemRepository) UpdateItem(ctx context.Context, order entity.Order, item entity.Item) (entity.Item, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := saveOrderTotals(tx, order); err != nil {
+			return err
+		}
+
+		result := tx.Model(&entity.Item{}).
+			Where("id = ? AND order_id = ?", item.ID, order.ID).
+			Select("name", "description", "quantity", "unit_price", "currency", "line_total").
+			Updates(&item)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrItemNotFound
+		}
+
+		if err := tx.Take(&item, "id = ?", item.ID).Error; err != nil {
+			return err
+		}
+
+		return enqueueEvent(tx, order.ID, entity.EventItemUpdated, item.ToViewModel())
+	})
+
+	return item, err
+}
+
+func (r *ItemRepository) DeleteItem(ctx context.Context, order entity.Order, itemID int64) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := saveOrderTotals(tx, order); err != nil {
+			return err
+		}
+
+		result := tx.Where("order_id = ?", order.ID).Delete(&entity.Item{}, itemID)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrItemNotFound
+		}
+
+		return enqueueEvent(tx, order.ID, entity.EventItemDeleted, entity.ItemDeletedData{ID: itemID})
+	})
+}
+
+func saveOrderTotals(tx *gorm.DB, order entity.Order) error {
+	result := tx.Model(&entity.Order{}).
+		Where("id = ? AND version = ?", order.ID, order.Version).
+		Updates(map[string]interface{}{
+			"currency": order.Currency,
+			"subtotal": order.Subtotal,
+			"total":    order.Total,
+			"version":  gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		if err := orderExists(tx, order.ID); err != nil {
+			return err
+		}
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateItem(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	item := createRandomItem()
+	order.Total = order.Total.Add(item.UnitPrice.Mul(decimal.NewFromInt32(item.Quantity)))
+
+	created, err := testItemRepo.CreateItem(context.Background(), order, item)
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+	require.Equal(t, order.ID, created.OrderID)
+
+	updated, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, len(order.Items)+1, len(updated.Items))
+	require.Equal(t, order.Version+1, updated.Version)
+	require.True(t, order.Total.Equal(updated.Total))
+}
+
+func TestUpdateItem(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	item := order.Items[0]
+	item.Quantity++
+
+	updated, err := testItemRepo.UpdateItem(context.Background(), order, item)
+	require.NoError(t, err)
+	require.Equal(t, item.Quantity, updated.Quantity)
+
+	// order still carries the version it was read at.
+	_, err = testItemRepo.UpdateItem(context.Background(), order, item)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+}
+
+func TestUpdateItemOfOtherOrder(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	other := createRandomOrder(t)
+
+	_, err := testItemRepo.UpdateItem(context.Background(), order, other.Items[0])
+	require.ErrorIs(t, err, ErrItemNotFound)
+
+	unchanged, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, order.Version, unchanged.Version)
+}
+
+func TestDeleteItem(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+
+	err := testItemRepo.DeleteItem(context.Background(), order, order.Items[0].ID)
+	require.NoError(t, err)
+
+	updated, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, len(order.Items)-1, len(updated.Items))
+	require.Equal(t, order.Version+1, updated.Version)
+}
+
+func TestDeleteItemNotFound(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	other := createRandomOrder(t)
+
+	err := testItemRepo.DeleteItem(context.Background(), order, other.Items[0].ID)
+	require.ErrorIs(t, err, ErrItemNotFound)
+
+	missing := order
+	missing.ID = other.ID + 1
+	err = testItemRepo.DeleteItem(context.Background(), missing, order.Items[0].ID)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+}
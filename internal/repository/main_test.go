@@ -19,10 +19,17 @@ import (
 )
 
 var (
-	testDB        *gorm.DB
-	testOrderRepo *OrderRepository
-	pool          *dockertest.Pool
-	resource      *dockertest.Resource
+	testDB              *gorm.DB
+	testOrderRepo       *OrderRepository
+	testItemRepo        *ItemRepository
+	testIdempotencyRepo *IdempotencyRepository
+	testAuditRepo       *AuditRepository
+	testOutboxRepo      *OutboxRepository
+	testWebhookRepo     *WebhookRepository
+	testAPIKeyRepo      *APIKeyRepository
+	testTransactor      *Transactor
+	pool                *dockertest.Pool
+	resource            *dockertest.Resource
 )
 
 const useDocker = true
@@ -127,9 +134,46 @@ func setUpDatabase(cfg config.Config) error {
 		if err != nil {
 			log.Fatal("Couldn't create table items")
 		}
+
+		err = testDB.AutoMigrate(&entity.OrderStatusTransition{})
+		if err != nil {
+			log.Fatal("Couldn't create table order_status_transitions")
+		}
+
+		err = testDB.AutoMigrate(&entity.IdempotencyKey{})
+		if err != nil {
+			log.Fatal("Couldn't create table idempotency_keys")
+		}
+
+		err = testDB.AutoMigrate(&entity.AuditEntry{})
+		if err != nil {
+			log.Fatal("Couldn't create table audit_entries")
+		}
+
+		err = testDB.AutoMigrate(&entity.OutboxEvent{})
+		if err != nil {
+			log.Fatal("Couldn't create table outbox_events")
+		}
+
+		err = testDB.AutoMigrate(&entity.WebhookSubscription{}, &entity.WebhookDelivery{}, &entity.WebhookDeliveryAttempt{})
+		if err != nil {
+			log.Fatal("Couldn't create webhook tables")
+		}
+
+		err = testDB.AutoMigrate(&entity.APIKey{})
+		if err != nil {
+			log.Fatal("Couldn't create table api_keys")
+		}
 	}
 
 	testOrderRepo = NewOrderRepository(testDB)
+	testItemRepo = NewItemRepository(testDB)
+	testIdempotencyRepo = NewIdempotencyRepository(testDB)
+	testAuditRepo = NewAuditRepository(testDB)
+	testOutboxRepo = NewOutboxRepository(testDB)
+	testWebhookRepo = NewWebhookRepository(testDB)
+	testAPIKeyRepo = NewAPIKeyRepository(testDB)
+	testTransactor = NewTransactor(testDB)
 
 	return nil
 }
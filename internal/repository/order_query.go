@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"fmt"
+	"simple-order-go/internal/entity"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// orderItems joins an order to the items that are listed with it: its live
+// items, or for a deleted order the items deleted together with it.
+const orderItems = "items.order_id = orders.id AND items.deleted_at IS NOT DISTINCT FROM orders.deleted_at"
+
+// Item sort keys use the "C" collation so that they order the same way as
+// Go string comparison, which the service relies on to build cursors.
+var orderSortExpressions = map[entity.OrderSortField]string{
+	entity.OrderSortCreatedAt:       "orders.created_at",
+	entity.OrderSortCustomerName:    "orders.customer_name",
+	entity.OrderSortOrderedAt:       "orders.ordered_at",
+	entity.OrderSortItemDescription: `(SELECT COALESCE(MIN(items.description COLLATE "C"), '') FROM items WHERE ` + orderItems + ")",
+	entity.OrderSortItemQuantity:    "(SELECT COALESCE(MAX(items.quantity), 0) FROM items WHERE " + orderItems + ")",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func sortExpression(field entity.OrderSortField) string {
+	if expr, ok := orderSortExpressions[field]; ok {
+		return expr
+	}
+	return orderSortExpressions[entity.OrderSortCreatedAt]
+}
+
+// listOrders starts a query for the orders matching query, with their items.
+func listOrders(db *gorm.DB, query entity.OrderQuery) *gorm.DB {
+	if query.Filter.IncludeDeleted {
+		db = db.Unscoped()
+	}
+	return db.Model(&entity.Order{}).Preload("Items").
+		Scopes(filterOrders(query.Filter), sortOrders(query.Sort))
+}
+
+// hideDeletedItems drops the items that don't belong in an order's listing,
+// see orderItems. Only listings that include deleted orders load them.
+func hideDeletedItems(orders []entity.Order) []entity.Order {
+	for i, order := range orders {
+		items := order.Items[:0]
+		for _, item := range order.Items {
+			if item.DeletedAt.Valid == order.DeletedAt.Valid && item.DeletedAt.Time.Equal(order.DeletedAt.Time) {
+				items = append(items, item)
+			}
+		}
+		orders[i].Items = items
+	}
+	return orders
+}
+
+func filterOrders(f entity.OrderFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.Owner != "" {
+			db = db.Where("orders.owner = ?", f.Owner)
+		}
+		if f.CustomerName != "" {
+			db = db.Where("orders.customer_name = ?", f.CustomerName)
+		}
+		if f.CustomerNamePrefix != "" {
+			db = db.Where("orders.customer_name LIKE ?", likeEscaper.Replace(f.CustomerNamePrefix)+"%")
+		}
+		if f.OrderedFrom != nil {
+			db = db.Where("orders.ordered_at >= ?", *f.OrderedFrom)
+		}
+		if f.OrderedTo != nil {
+			db = db.Where("orders.ordered_at <= ?", *f.OrderedTo)
+		}
+		if f.ItemDescription != "" {
+			db = db.Where("EXISTS (SELECT 1 FROM items WHERE "+orderItems+" AND items.description = ?)", f.ItemDescription)
+		}
+		if f.MinItemQuantity > 0 {
+			db = db.Where("EXISTS (SELECT 1 FROM items WHERE "+orderItems+" AND items.quantity >= ?)", f.MinItemQuantity)
+		}
+		return db
+	}
+}
+
+func sortOrders(s entity.OrderSort) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+		return db.Order(fmt.Sprintf("%s %s, orders.id %s", sortExpression(s.Field), dir, dir))
+	}
+}
@@ -1,107 +1,304 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"simple-order-go/internal/apperror"
 	"simple-order-go/internal/entity"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrOrderNotFound   = apperror.NotFound("order_not_found", "order not found")
+	ErrItemNotFound    = apperror.NotFound("item_not_found", "item not found in order")
+	ErrOrderNotDeleted = apperror.Conflict("order_not_deleted", "order is not deleted")
+	ErrStatusChanged   = apperror.Conflict("status_changed", "order status changed concurrently")
+	ErrVersionMismatch = apperror.Precondition("version_mismatch", "order version does not match")
+	ErrUnknownItem     = apperror.Validation("unknown_item", "item does not belong to order")
+)
+
 type OrderRepository struct {
 	db *gorm.DB
 }
 
+// IOrderRepository stores orders. Every change writes a domain event to the
+// outbox in the same transaction. Deleting an order only marks it and its
+// items as deleted; deleted orders are hidden from everything but listings
+// that ask for them and RestoreOrder, until PurgeOrders removes them. Methods
+// that read or change a single order return ErrOrderNotFound if it doesn't
+// exist or is deleted.
 type IOrderRepository interface {
-	CreateOrder(order entity.Order) (entity.Order, error)
-	GetOrder(orderID int64) (entity.Order, error)
-	GetAllOrders() (entity.Orders, error)
-	UpdateOrder(order entity.Order) error
-	DeleteOrder(orderID int64) error
+	CreateOrder(ctx context.Context, order entity.Order) (entity.Order, error)
+	GetOrder(ctx context.Context, orderID int64) (entity.Order, error)
+	GetOrdersPage(ctx context.Context, query entity.OrderQuery, limit int, after *entity.OrderCursor) (entity.Orders, error)
+	UpdateOrder(ctx context.Context, order entity.Order) error
+	DeleteOrder(ctx context.Context, orderID int64, version int64) error
+	RestoreOrder(ctx context.Context, orderID int64) error
+	PurgeOrders(ctx context.Context, deletedBefore time.Time) (entity.Orders, error)
+	UpdateOrderStatus(ctx context.Context, orderID int64, from, to entity.OrderStatus) error
+	GetOrderStatusTransitions(ctx context.Context, orderID int64) ([]entity.OrderStatusTransition, error)
 }
 
 func NewOrderRepository(db *gorm.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-func (r *OrderRepository) CreateOrder(order entity.Order) (entity.Order, error) {
-	err := r.db.Transaction(func(tx *gorm.DB) error {
-		err := r.db.Create(&order).Error
+func (r *OrderRepository) CreateOrder(ctx context.Context, order entity.Order) (entity.Order, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&order).Error
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueEvent(tx, order.ID, entity.EventOrderCreated, order.ToViewModel())
 	})
 
 	return order, err
 }
 
-func (r *OrderRepository) GetOrder(orderID int64) (order entity.Order, err error) {
-	err = r.db.Model(&entity.Order{}).Preload("Items").Take(&order, "orders.id = ?", orderID).Error
+func (r *OrderRepository) GetOrder(ctx context.Context, orderID int64) (order entity.Order, err error) {
+	err = conn(ctx, r.db).Model(&entity.Order{}).Preload("Items").Take(&order, "orders.id = ?", orderID).Error
+	err = orderNotFound(err)
 	return
 }
 
-func (r *OrderRepository) GetAllOrders() (entity.Orders, error) {
+func (r *OrderRepository) GetOrdersPage(ctx context.Context, query entity.OrderQuery, limit int, after *entity.OrderCursor) (entity.Orders, error) {
 	var orders []entity.Order
-	err := r.db.Unscoped().Model(&entity.Order{}).Preload("Items").Find(&orders).Error
-	return orders, err
+	tx := listOrders(conn(ctx, r.db), query)
+	if after != nil {
+		op := ">"
+		if query.Sort.Desc {
+			op = "<"
+		}
+		tx = tx.Where(fmt.Sprintf("(%s, orders.id) %s (?, ?)", sortExpression(query.Sort.Field), op), after.Value, after.ID)
+	}
+
+	err := tx.Limit(limit).Find(&orders).Error
+	return hideDeletedItems(orders), err
 }
 
-func (r *OrderRepository) UpdateOrder(order entity.Order) error {
-	err := r.db.Transaction(func(tx *gorm.DB) error {
-		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&entity.Order{}, "id = ?", order.ID).Error
+// UpdateOrder replaces an order and its items if its Version still matches
+// the stored one, and bumps the stored version. Items are matched by ID:
+// items without an ID are added, stored items missing from order.Items are
+// deleted, and an ID belonging to another order fails with ErrUnknownItem.
+// It returns ErrVersionMismatch if the version moved.
+func (r *OrderRepository) UpdateOrder(ctx context.Context, order entity.Order) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var current entity.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&current, "id = ?", order.ID).Error
 		if err != nil {
-			return err
+			return orderNotFound(err)
 		}
 
-		err = tx.Omit("Items").Save(&order).Error
+		if current.Version != order.Version {
+			return ErrVersionMismatch
+		}
+		order.Version++
+
+		err = tx.Omit("Items", "Owner", "Status", "CreatedAt").Save(&order).Error
 		if err != nil {
 			return err
 		}
 
+		// Items without an ID are new; items missing from the order are gone.
+		keep := make([]int64, 0, len(order.Items))
 		for _, item := range order.Items {
-			var exist entity.Item
-			result := tx.Where("order_id = ?", order.ID).Where("description = ?", item.Description).
-				Attrs(entity.Item{
-					Name:        item.Name,
-					Description: item.Description,
-					Quantity:    item.Quantity,
-					OrderID:     order.ID,
-				}).
-				FirstOrCreate(&exist)
-			if result.Error != nil {
-				return err
+			if item.ID != 0 {
+				keep = append(keep, item.ID)
 			}
+		}
 
-			if result.RowsAffected == 0 {
-				err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-					Where("id = ?", exist.ID).
-					First(&exist).Error
-				if err != nil {
-					return err
-				}
+		removed := tx.Where("order_id = ?", order.ID)
+		if len(keep) > 0 {
+			removed = removed.Where("id NOT IN ?", keep)
+		}
+		if err := removed.Delete(&entity.Item{}).Error; err != nil {
+			return err
+		}
 
-				err = tx.Model(&exist).
-					Where("id = ?", exist.ID).
-					Updates(entity.Item{Name: item.Name, Quantity: item.Quantity}).
-					Error
-				if err != nil {
+		for _, item := range order.Items {
+			item.OrderID = order.ID
+
+			if item.ID == 0 {
+				if err := tx.Create(&item).Error; err != nil {
 					return err
 				}
+				continue
+			}
+
+			result := tx.Model(&entity.Item{}).
+				Where("id = ? AND order_id = ?", item.ID, order.ID).
+				Select("name", "description", "quantity", "unit_price", "currency", "line_total").
+				Updates(&item)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				return ErrUnknownItem
 			}
 		}
 
-		return nil
+		return enqueueOrderEvent(tx, order.ID, entity.EventOrderUpdated)
 	})
 
 	return err
 }
 
-func (r *OrderRepository) DeleteOrder(orderID int64) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Unscoped().Delete(&entity.Order{}, orderID).Error; err != nil {
+// DeleteOrder marks an order and its items as deleted if the order is still at
+// the given version. It returns ErrVersionMismatch if the order exists at
+// another version.
+func (r *OrderRepository) DeleteOrder(ctx context.Context, orderID int64, version int64) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Items share the order's deletion time, which is how RestoreOrder
+		// tells them from items that were deleted on their own earlier.
+		now := time.Now()
+
+		result := tx.Model(&entity.Order{}).Where("id = ? AND version = ?", orderID, version).
+			Updates(map[string]interface{}{"deleted_at": now, "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			if err := orderExists(tx, orderID); err != nil {
+				return err
+			}
+			return ErrVersionMismatch
+		}
+
+		err := tx.Model(&entity.Item{}).Where("order_id = ?", orderID).Update("deleted_at", now).Error
+		if err != nil {
 			return err
 		}
-		return nil
+
+		return enqueueEvent(tx, orderID, entity.EventOrderDeleted, entity.OrderDeletedData{ID: orderID})
 	})
 }
+
+// RestoreOrder undoes DeleteOrder, bringing back the items that were deleted
+// along with the order. It returns ErrOrderNotDeleted if the order isn't
+// deleted.
+func (r *OrderRepository) RestoreOrder(ctx context.Context, orderID int64) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var order entity.Order
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Take(&order, "id = ?", orderID).Error
+		if err != nil {
+			return orderNotFound(err)
+		}
+
+		if !order.DeletedAt.Valid {
+			return ErrOrderNotDeleted
+		}
+
+		err = tx.Unscoped().Model(&entity.Item{}).
+			Where("order_id = ? AND deleted_at = ?", orderID, order.DeletedAt.Time).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Model(&entity.Order{}).Where("id = ?", orderID).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+
+		return enqueueOrderEvent(tx, orderID, entity.EventOrderRestored)
+	})
+}
+
+// PurgeOrders permanently removes the orders and items deleted before
+// deletedBefore and returns the orders removed, as they were when deleted.
+func (r *OrderRepository) PurgeOrders(ctx context.Context, deletedBefore time.Time) (entity.Orders, error) {
+	var orders []entity.Order
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Lock the orders so none of them can be restored before they are
+		// removed.
+		err := tx.Unscoped().Model(&entity.Order{}).Preload("Items").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at < ?", deletedBefore).Order("id").Find(&orders).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Where("deleted_at < ?", deletedBefore).Delete(&entity.Item{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(orders) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, len(orders))
+		for i, order := range orders {
+			ids[i] = order.ID
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&entity.Order{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hideDeletedItems(orders), nil
+}
+
+// UpdateOrderStatus moves an order from one status to another and records the
+// transition. It returns ErrStatusChanged if the order is no longer in the
+// from status.
+func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID int64, from, to entity.OrderStatus) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Order{}).Where("id = ? AND status = ?", orderID, from).
+			Updates(map[string]interface{}{"status": to, "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			if err := orderExists(tx, orderID); err != nil {
+				return err
+			}
+			return ErrStatusChanged
+		}
+
+		err := tx.Create(&entity.OrderStatusTransition{
+			OrderID:    orderID,
+			FromStatus: from,
+			ToStatus:   to,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(tx, orderID, entity.EventOrderStatusChanged, entity.OrderStatusChangedData{
+			ID:   orderID,
+			From: from,
+			To:   to,
+		})
+	})
+}
+
+func (r *OrderRepository) GetOrderStatusTransitions(ctx context.Context, orderID int64) ([]entity.OrderStatusTransition, error) {
+	var transitions []entity.OrderStatusTransition
+	err := conn(ctx, r.db).Where("order_id = ?", orderID).Order("created_at, id").Find(&transitions).Error
+	return transitions, err
+}
+
+// orderExists returns ErrOrderNotFound if there is no order with the given
+// ID. Statements that match no rows use it to tell a missing order from one
+// that failed their condition.
+func orderExists(tx *gorm.DB, orderID int64) error {
+	return orderNotFound(tx.Select("id").Take(&entity.Order{}, "id = ?", orderID).Error)
+}
+
+func orderNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOrderNotFound
+	}
+	return err
+}
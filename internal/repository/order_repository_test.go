@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"context"
 	"simple-order-go/common"
 	"simple-order-go/internal/entity"
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -23,7 +25,7 @@ func createRandomOrder(t *testing.T) entity.Order {
 		Items:        items,
 	}
 
-	order, err := testOrderRepo.CreateOrder(arg)
+	order, err := testOrderRepo.CreateOrder(context.Background(), arg)
 
 	require.NoError(t, err)
 	require.Equal(t, arg.CustomerName, order.CustomerName)
@@ -37,6 +39,8 @@ func createRandomItem() (item entity.Item) {
 		Name:        common.RandomName(),
 		Description: common.RandomString(10),
 		Quantity:    int32(common.RandomInt(1, 100)),
+		UnitPrice:   decimal.NewFromInt(common.RandomInt(1, 1000)),
+		Currency:    "USD",
 	}
 	return
 }
@@ -52,7 +56,7 @@ func TestGetOrder(t *testing.T) {
 
 	order1 := createRandomOrder(t)
 
-	order2, err := testOrderRepo.GetOrder(order1.ID)
+	order2, err := testOrderRepo.GetOrder(context.Background(), order1.ID)
 
 	require.NoError(t, err)
 	require.Equal(t, order1.CustomerName, order2.CustomerName)
@@ -60,19 +64,151 @@ func TestGetOrder(t *testing.T) {
 	require.Equal(t, len(order1.Items), len(order2.Items))
 }
 
-func TestGetAllOrders(t *testing.T) {
+// firstPage lists the orders matching query, of which the tests create fewer
+// than a page.
+func firstPage(query entity.OrderQuery) (entity.Orders, error) {
+	return testOrderRepo.GetOrdersPage(context.Background(), query, 100, nil)
+}
+
+func TestGetOrdersPageUnderLimit(t *testing.T) {
 	defer tearDown()
 
 	for i := 1; i <= 10; i++ {
 		createRandomOrder(t)
 	}
 
-	orders, err := testOrderRepo.GetAllOrders()
+	orders, err := firstPage(entity.OrderQuery{})
 
 	require.NoError(t, err)
 	require.Equal(t, 10, len(orders))
 }
 
+func TestGetOrdersPageFiltered(t *testing.T) {
+	defer tearDown()
+
+	for i := 1; i <= 5; i++ {
+		createRandomOrder(t)
+	}
+
+	order := createRandomOrder(t)
+	from := order.OrderedAt.Add(-time.Millisecond)
+
+	testCases := []struct {
+		name   string
+		filter entity.OrderFilter
+		want   int
+	}{
+		{name: "CustomerName", filter: entity.OrderFilter{CustomerName: order.CustomerName}, want: 1},
+		{name: "CustomerNamePrefix", filter: entity.OrderFilter{CustomerNamePrefix: order.CustomerName[:5]}, want: 1},
+		{name: "OrderedFrom", filter: entity.OrderFilter{OrderedFrom: &from}, want: 1},
+		{name: "ItemDescription", filter: entity.OrderFilter{ItemDescription: order.Items[0].Description}, want: 1},
+		{name: "MinItemQuantity", filter: entity.OrderFilter{MinItemQuantity: 101}, want: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orders, err := firstPage(entity.OrderQuery{Filter: tc.filter})
+			require.NoError(t, err)
+			require.Equal(t, tc.want, len(orders))
+		})
+	}
+}
+
+func TestGetOrdersPageByOwner(t *testing.T) {
+	defer tearDown()
+
+	createRandomOrder(t)
+
+	arg := entity.Order{CustomerName: common.RandomName(), Owner: "customer-1", OrderedAt: time.Now()}
+	owned, err := testOrderRepo.CreateOrder(context.Background(), arg)
+	require.NoError(t, err)
+
+	// Updates keep the owner.
+	owned.Owner = ""
+	err = testOrderRepo.UpdateOrder(context.Background(), owned)
+	require.NoError(t, err)
+
+	orders, err := firstPage(entity.OrderQuery{
+		Filter: entity.OrderFilter{Owner: "customer-1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(orders))
+	require.Equal(t, owned.ID, orders[0].ID)
+	require.Equal(t, "customer-1", orders[0].Owner)
+}
+
+func TestGetOrdersPageSortedDesc(t *testing.T) {
+	defer tearDown()
+
+	for i := 1; i <= 5; i++ {
+		createRandomOrder(t)
+	}
+
+	orders, err := firstPage(entity.OrderQuery{
+		Sort: entity.OrderSort{Field: entity.OrderSortCustomerName, Desc: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5, len(orders))
+
+	for i := 1; i < len(orders); i++ {
+		require.GreaterOrEqual(t, orders[i-1].CustomerName, orders[i].CustomerName)
+	}
+}
+
+func TestGetOrdersPageSorted(t *testing.T) {
+	defer tearDown()
+
+	for i := 1; i <= 5; i++ {
+		createRandomOrder(t)
+	}
+
+	query := entity.OrderQuery{Sort: entity.OrderSort{Field: entity.OrderSortCustomerName}}
+
+	first, err := testOrderRepo.GetOrdersPage(context.Background(), query, 3, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(first))
+
+	last := first[len(first)-1]
+	second, err := testOrderRepo.GetOrdersPage(context.Background(), query, 3, &entity.OrderCursor{Value: last.CustomerName, ID: last.ID})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(second))
+	require.GreaterOrEqual(t, second[0].CustomerName, last.CustomerName)
+}
+
+func TestGetOrdersPage(t *testing.T) {
+	defer tearDown()
+
+	for i := 1; i <= 10; i++ {
+		createRandomOrder(t)
+	}
+
+	var after *entity.OrderCursor
+	seen := make(map[int64]bool)
+
+	for page := 0; page < 3; page++ {
+		orders, err := testOrderRepo.GetOrdersPage(context.Background(), entity.OrderQuery{}, 4, after)
+		require.NoError(t, err)
+
+		if page < 2 {
+			require.Equal(t, 4, len(orders))
+		} else {
+			require.Equal(t, 2, len(orders))
+		}
+
+		for _, order := range orders {
+			require.False(t, seen[order.ID])
+			seen[order.ID] = true
+		}
+
+		last := orders[len(orders)-1]
+		after = &entity.OrderCursor{Value: last.CreatedAt, ID: last.ID}
+	}
+
+	orders, err := testOrderRepo.GetOrdersPage(context.Background(), entity.OrderQuery{}, 4, after)
+	require.NoError(t, err)
+	require.Empty(t, orders)
+}
+
 func TestUpdateToCreateOrder(t *testing.T) {
 	defer tearDown()
 
@@ -100,7 +236,7 @@ func TestUpdateToCreateOrder(t *testing.T) {
 
 			start <- true
 
-			err := testOrderRepo.UpdateOrder(order)
+			err := testOrderRepo.UpdateOrder(context.Background(), order)
 
 			updErrs <- err
 			itemsLen <- len(order.Items)
@@ -112,7 +248,7 @@ func TestUpdateToCreateOrder(t *testing.T) {
 
 			time.Sleep(2 * time.Millisecond)
 
-			o, err := testOrderRepo.GetOrder(orderID)
+			o, err := testOrderRepo.GetOrder(context.Background(), orderID)
 
 			getErrs <- err
 			result <- o
@@ -161,7 +297,7 @@ func TestUpdateOrder(t *testing.T) {
 
 			start <- true
 
-			err := testOrderRepo.UpdateOrder(order)
+			err := testOrderRepo.UpdateOrder(context.Background(), order)
 
 			updErrs <- err
 			custNames <- name
@@ -174,7 +310,7 @@ func TestUpdateOrder(t *testing.T) {
 
 			time.Sleep(1 * time.Millisecond)
 
-			order, err := testOrderRepo.GetOrder(orderID)
+			order, err := testOrderRepo.GetOrder(context.Background(), orderID)
 
 			getErrs <- err
 			results <- order
@@ -204,19 +340,271 @@ func TestDeleteOrder(t *testing.T) {
 
 	order := createRandomOrder(t)
 
-	err := testOrderRepo.DeleteOrder(order.ID)
+	err := testOrderRepo.DeleteOrder(context.Background(), order.ID, order.Version)
 	require.NoError(t, err)
 
-	delOrder, err := testOrderRepo.GetOrder(order.ID)
-	require.Error(t, err)
+	delOrder, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.ErrorIs(t, err, ErrOrderNotFound)
 	require.Equal(t, delOrder.ID, int64(0))
 }
 
+func TestGetOrderNotFound(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+
+	_, err := testOrderRepo.GetOrder(context.Background(), order.ID+1)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+func TestUpdateOrderNotFound(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	order.ID++
+
+	err := testOrderRepo.UpdateOrder(context.Background(), order)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+// Deleting a missing order must not report success, whatever the version.
+func TestDeleteOrderNotFound(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+
+	err := testOrderRepo.DeleteOrder(context.Background(), order.ID+1, order.Version)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+
+	err = testOrderRepo.DeleteOrder(context.Background(), order.ID, order.Version)
+	require.NoError(t, err)
+
+	err = testOrderRepo.DeleteOrder(context.Background(), order.ID, order.Version)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+func TestGetOrdersPageIncludeDeleted(t *testing.T) {
+	defer tearDown()
+
+	live := createRandomOrder(t)
+	deleted := createRandomOrder(t)
+
+	err := testOrderRepo.DeleteOrder(context.Background(), deleted.ID, deleted.Version)
+	require.NoError(t, err)
+
+	orders, err := firstPage(entity.OrderQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(orders))
+	require.Equal(t, live.ID, orders[0].ID)
+
+	query := entity.OrderQuery{Filter: entity.OrderFilter{IncludeDeleted: true}}
+	orders, err = firstPage(query)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(orders))
+	require.Equal(t, deleted.ID, orders[1].ID)
+	require.True(t, orders[1].DeletedAt.Valid)
+	require.Equal(t, len(deleted.Items), len(orders[1].Items))
+}
+
+// RestoreOrder brings back the items deleted with the order, but not items
+// that were deleted before it.
+func TestRestoreOrder(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	for len(order.Items) < 2 {
+		order = createRandomOrder(t)
+	}
+
+	err := testItemRepo.DeleteItem(context.Background(), order, order.Items[0].ID)
+	require.NoError(t, err)
+
+	err = testOrderRepo.RestoreOrder(context.Background(), order.ID)
+	require.ErrorIs(t, err, ErrOrderNotDeleted)
+
+	err = testOrderRepo.DeleteOrder(context.Background(), order.ID, order.Version+1)
+	require.NoError(t, err)
+
+	err = testOrderRepo.RestoreOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+
+	restored, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, len(order.Items)-1, len(restored.Items))
+	require.Equal(t, order.Version+3, restored.Version)
+
+	err = testOrderRepo.RestoreOrder(context.Background(), order.ID+1)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+func TestPurgeOrders(t *testing.T) {
+	defer tearDown()
+
+	old := createRandomOrder(t)
+	recent := createRandomOrder(t)
+	live := createRandomOrder(t)
+
+	err := testOrderRepo.DeleteOrder(context.Background(), old.ID, old.Version)
+	require.NoError(t, err)
+
+	cutoff := time.Now()
+
+	err = testOrderRepo.DeleteOrder(context.Background(), recent.ID, recent.Version)
+	require.NoError(t, err)
+
+	purged, err := testOrderRepo.PurgeOrders(context.Background(), cutoff)
+	require.NoError(t, err)
+	require.Len(t, purged, 1)
+	require.Equal(t, old.ID, purged[0].ID)
+	require.Equal(t, len(old.Items), len(purged[0].Items))
+
+	err = testOrderRepo.RestoreOrder(context.Background(), old.ID)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+
+	err = testOrderRepo.RestoreOrder(context.Background(), recent.ID)
+	require.NoError(t, err)
+
+	_, err = testOrderRepo.GetOrder(context.Background(), live.ID)
+	require.NoError(t, err)
+}
+
+// UpdateOrder replaces the order's items: items are matched by ID, items
+// without an ID are inserted and stored items left out are deleted.
+func TestUpdateOrderReplacesItems(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	for len(order.Items) < 2 {
+		order = createRandomOrder(t)
+	}
+
+	kept := order.Items[0]
+	kept.Name = common.RandomName()
+	kept.Quantity++
+	added := createRandomItem()
+
+	order.Items = []entity.Item{kept, added}
+	err := testOrderRepo.UpdateOrder(context.Background(), order)
+	require.NoError(t, err)
+
+	updated, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(updated.Items))
+
+	byID := make(map[int64]entity.Item)
+	for _, item := range updated.Items {
+		byID[item.ID] = item
+	}
+
+	require.Contains(t, byID, kept.ID)
+	require.Equal(t, kept.Name, byID[kept.ID].Name)
+	require.Equal(t, kept.Quantity, byID[kept.ID].Quantity)
+
+	delete(byID, kept.ID)
+	for _, item := range byID {
+		require.Equal(t, added.Description, item.Description)
+	}
+}
+
+func TestUpdateOrderRemovesAllItems(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	order.Items = nil
+
+	err := testOrderRepo.UpdateOrder(context.Background(), order)
+	require.NoError(t, err)
+
+	updated, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Empty(t, updated.Items)
+}
+
+func TestUpdateOrderUnknownItem(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	other := createRandomOrder(t)
+
+	name := order.CustomerName
+	order.CustomerName = common.RandomName()
+	order.Items = []entity.Item{other.Items[0]}
+
+	err := testOrderRepo.UpdateOrder(context.Background(), order)
+	require.ErrorIs(t, err, ErrUnknownItem)
+
+	// Nothing is changed when the update is rejected.
+	unchanged, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, name, unchanged.CustomerName)
+	require.NotEmpty(t, unchanged.Items)
+
+	otherUnchanged, err := testOrderRepo.GetOrder(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.Equal(t, len(other.Items), len(otherUnchanged.Items))
+}
+
+func TestUpdateOrderVersionMismatch(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+	require.Equal(t, int64(1), order.Version)
+
+	order.CustomerName = common.RandomName()
+	err := testOrderRepo.UpdateOrder(context.Background(), order)
+	require.NoError(t, err)
+
+	updated, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), updated.Version)
+
+	// order still carries the version it was read at.
+	err = testOrderRepo.UpdateOrder(context.Background(), order)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	err = testOrderRepo.DeleteOrder(context.Background(), order.ID, order.Version)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	err = testOrderRepo.DeleteOrder(context.Background(), order.ID, updated.Version)
+	require.NoError(t, err)
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+
+	err := testOrderRepo.UpdateOrderStatus(context.Background(), order.ID, entity.OrderStatusPending, entity.OrderStatusConfirmed)
+	require.NoError(t, err)
+
+	err = testOrderRepo.UpdateOrderStatus(context.Background(), order.ID, entity.OrderStatusPending, entity.OrderStatusCancelled)
+	require.ErrorIs(t, err, ErrStatusChanged)
+
+	err = testOrderRepo.UpdateOrderStatus(context.Background(), order.ID+1, entity.OrderStatusPending, entity.OrderStatusConfirmed)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+
+	updated, err := testOrderRepo.GetOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.OrderStatusConfirmed, updated.Status)
+
+	transitions, err := testOrderRepo.GetOrderStatusTransitions(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(transitions))
+	require.Equal(t, entity.OrderStatusPending, transitions[0].FromStatus)
+	require.Equal(t, entity.OrderStatusConfirmed, transitions[0].ToStatus)
+}
+
 func tearDown() {
 	tx := testDB.Begin()
 	defer tx.Rollback()
 
 	tx.Exec("DELETE FROM orders")
+	tx.Exec("DELETE FROM audit_entries")
+	tx.Exec("DELETE FROM outbox_events")
+	tx.Exec("DELETE FROM webhook_delivery_attempts")
+	tx.Exec("DELETE FROM webhook_deliveries")
+	tx.Exec("DELETE FROM webhook_subscriptions")
+	tx.Exec("DELETE FROM api_keys")
 
 	tx.Commit()
 }
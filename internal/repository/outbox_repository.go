@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"simple-order-go/internal/entity"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+// IOutboxRepository hands out the events that repositories write to the
+// outbox. A claimed event is leased to the caller; if it is neither marked
+// published nor failed before the lease runs out, it is claimed again.
+type IOutboxRepository interface {
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEvent, error)
+	MarkPublished(ctx context.Context, eventID int64) error
+	MarkFailed(ctx context.Context, eventID int64, reason string, retryAt time.Time) error
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// ClaimEvents claims up to limit unpublished events that are due, oldest
+// first. Events claimed by another caller are skipped.
+func (r *OutboxRepository) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEvent, error) {
+	db := conn(ctx, r.db)
+	due := db.Model(&entity.OutboxEvent{}).Select("id").
+		Where("published_at IS NULL AND available_at <= ?", time.Now()).
+		Order("id").Limit(limit).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
+	var events []entity.OutboxEvent
+	err := db.Model(&events).Clauses(clause.Returning{}).
+		Where("id IN (?)", due).
+		Updates(map[string]interface{}{
+			"available_at": time.Now().Add(lease),
+			"attempts":     gorm.Expr("attempts + 1"),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, eventID int64) error {
+	return conn(ctx, r.db).Model(&entity.OutboxEvent{}).Where("id = ?", eventID).
+		Update("published_at", time.Now()).Error
+}
+
+// MarkFailed records why publishing an event failed and when to try again.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, eventID int64, reason string, retryAt time.Time) error {
+	return conn(ctx, r.db).Model(&entity.OutboxEvent{}).Where("id = ?", eventID).
+		Updates(map[string]interface{}{"last_error": reason, "available_at": retryAt}).Error
+}
+
+// enqueueEvent writes an event about an order to the outbox as part of tx.
+func enqueueEvent(tx *gorm.DB, orderID int64, eventType entity.EventType, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&entity.OutboxEvent{
+		OrderID:     orderID,
+		Type:        eventType,
+		Payload:     payload,
+		AvailableAt: time.Now(),
+	}).Error
+}
+
+// enqueueOrderEvent writes an event carrying the order as it is stored in tx.
+func enqueueOrderEvent(tx *gorm.DB, orderID int64, eventType entity.EventType) error {
+	var order entity.Order
+	if err := tx.Preload("Items").Take(&order, "id = ?", orderID).Error; err != nil {
+		return err
+	}
+
+	return enqueueEvent(tx, orderID, eventType, order.ToViewModel())
+}
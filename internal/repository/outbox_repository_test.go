@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"simple-order-go/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderChangesWriteEvents(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+
+	err := testOrderRepo.UpdateOrderStatus(context.Background(), order.ID, entity.OrderStatusPending, entity.OrderStatusConfirmed)
+	require.NoError(t, err)
+
+	// Changes that fail write no event.
+	err = testItemRepo.DeleteItem(context.Background(), order, order.Items[0].ID)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	err = testOrderRepo.DeleteOrder(context.Background(), order.ID, order.Version+1)
+	require.NoError(t, err)
+
+	events, err := testOutboxRepo.ClaimEvents(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(events))
+	require.Equal(t, entity.EventOrderCreated, events[0].Type)
+	require.Equal(t, entity.EventOrderStatusChanged, events[1].Type)
+	require.Equal(t, entity.EventOrderDeleted, events[2].Type)
+
+	var created entity.OrderViewModel
+	err = json.Unmarshal(events[0].Payload, &created)
+	require.NoError(t, err)
+	require.Equal(t, order.ID, created.ID)
+	require.Equal(t, len(order.Items), len(created.Items))
+}
+
+func TestClaimEvents(t *testing.T) {
+	defer tearDown()
+
+	order := createRandomOrder(t)
+
+	events, err := testOutboxRepo.ClaimEvents(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(events))
+	require.Equal(t, order.ID, events[0].OrderID)
+	require.Equal(t, 1, events[0].Attempts)
+
+	// Claimed events are leased.
+	leased, err := testOutboxRepo.ClaimEvents(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, leased)
+
+	err = testOutboxRepo.MarkFailed(context.Background(), events[0].ID, "unreachable", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+
+	retried, err := testOutboxRepo.ClaimEvents(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(retried))
+	require.Equal(t, 2, retried[0].Attempts)
+	require.Equal(t, "unreachable", retried[0].LastError)
+
+	err = testOutboxRepo.MarkPublished(context.Background(), events[0].ID)
+	require.NoError(t, err)
+
+	err = testOutboxRepo.MarkFailed(context.Background(), events[0].ID, "late", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+
+	published, err := testOutboxRepo.ClaimEvents(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, published)
+}
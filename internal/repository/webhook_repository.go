@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSubscriptionNotFound = apperror.NotFound("webhook_not_found", "webhook subscription not found")
+	ErrDeliveryNotFound     = apperror.NotFound("delivery_not_found", "webhook delivery not found")
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// IWebhookRepository stores webhook subscriptions and the deliveries of
+// events to them. Like the outbox, a claimed delivery is leased to the caller
+// until it records an attempt or the lease runs out.
+type IWebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription entity.WebhookSubscription) (entity.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, subscriptionID int64) (entity.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID int64) error
+	EnqueueDeliveries(ctx context.Context, event entity.Event) (int64, error)
+	GetDeliveries(ctx context.Context, subscriptionID int64) ([]entity.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (entity.WebhookDelivery, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, attempt entity.WebhookDeliveryAttempt, status entity.DeliveryStatus, nextAttemptAt time.Time) error
+	RedeliverDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (entity.WebhookDelivery, error)
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	err := conn(ctx, r.db).Create(&subscription).Error
+	return subscription, err
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, subscriptionID int64) (subscription entity.WebhookSubscription, err error) {
+	err = conn(ctx, r.db).Take(&subscription, "id = ?", subscriptionID).Error
+	err = subscriptionNotFound(err)
+	return
+}
+
+func (r *WebhookRepository) GetSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	var subscriptions []entity.WebhookSubscription
+	err := conn(ctx, r.db).Order("id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// DeleteSubscription removes a subscription along with its deliveries and
+// their logs.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, subscriptionID int64) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&entity.WebhookDelivery{}).Select("id").Where("subscription_id = ?", subscriptionID)
+		err := tx.Where("delivery_id IN (?)", deliveries).Delete(&entity.WebhookDeliveryAttempt{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("subscription_id = ?", subscriptionID).Delete(&entity.WebhookDelivery{}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Delete(&entity.WebhookSubscription{}, "id = ?", subscriptionID)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrSubscriptionNotFound
+		}
+		return nil
+	})
+}
+
+// EnqueueDeliveries creates a pending delivery of event for every
+// subscription to its type and returns how many it created. An event is
+// delivered to a subscription at most once, however often it is enqueued.
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, event entity.Event) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	eventTypes, err := json.Marshal([]entity.EventType{event.Type})
+	if err != nil {
+		return 0, err
+	}
+
+	result := conn(ctx, r.db).Exec(`
+		INSERT INTO webhook_deliveries
+			(subscription_id, event_id, event_type, payload, status, attempts, last_error, next_attempt_at, created_at, updated_at)
+		SELECT id, ?, ?, ?::jsonb, ?, 0, '', now(), now(), now()
+		FROM webhook_subscriptions
+		WHERE event_types @> ?::jsonb
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+		event.ID, event.Type, string(payload), entity.DeliveryPending, string(eventTypes))
+	return result.RowsAffected, result.Error
+}
+
+// GetDeliveries returns the deliveries to a subscription, newest first.
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, subscriptionID int64) ([]entity.WebhookDelivery, error) {
+	if _, err := r.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	var deliveries []entity.WebhookDelivery
+	err := conn(ctx, r.db).Where("subscription_id = ?", subscriptionID).Order("id DESC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetDelivery returns a delivery with its log, oldest attempt first.
+func (r *WebhookRepository) GetDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (delivery entity.WebhookDelivery, err error) {
+	err = conn(ctx, r.db).
+		Preload("Log", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Take(&delivery, "id = ? AND subscription_id = ?", deliveryID, subscriptionID).Error
+	err = deliveryNotFound(err)
+	return
+}
+
+// ClaimDeliveries claims up to limit pending deliveries that are due, oldest
+// first, along with their subscriptions. Each claim counts as an attempt.
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	db := conn(ctx, r.db)
+	due := db.Model(&entity.WebhookDelivery{}).Select("id").
+		Where("status = ? AND next_attempt_at <= ?", entity.DeliveryPending, time.Now()).
+		Order("id").Limit(limit).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
+	var claimed []entity.WebhookDelivery
+	err := db.Model(&claimed).Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("id IN (?)", due).
+		Updates(map[string]interface{}{
+			"next_attempt_at": time.Now().Add(lease),
+			"attempts":        gorm.Expr("attempts + 1"),
+			"updated_at":      time.Now(),
+		}).Error
+	if err != nil || len(claimed) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int64, len(claimed))
+	for i, delivery := range claimed {
+		ids[i] = delivery.ID
+	}
+
+	var deliveries []entity.WebhookDelivery
+	err = db.Preload("Subscription").Where("id IN ?", ids).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+// RecordAttempt logs an attempt and moves its delivery to status. A pending
+// delivery is tried again at nextAttemptAt.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, attempt entity.WebhookDeliveryAttempt, status entity.DeliveryStatus, nextAttemptAt time.Time) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+
+		changes := map[string]interface{}{
+			"status":          status,
+			"last_error":      attempt.Error,
+			"next_attempt_at": nextAttemptAt,
+			"updated_at":      time.Now(),
+		}
+		if status == entity.DeliverySucceeded {
+			changes["delivered_at"] = attempt.CreatedAt
+		}
+
+		return tx.Model(&entity.WebhookDelivery{}).Where("id = ?", attempt.DeliveryID).Updates(changes).Error
+	})
+}
+
+// RedeliverDelivery queues a delivery to be sent again right away, whatever
+// its status, with a fresh budget of attempts. Its log is kept.
+func (r *WebhookRepository) RedeliverDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (entity.WebhookDelivery, error) {
+	result := conn(ctx, r.db).Model(&entity.WebhookDelivery{}).
+		Where("id = ? AND subscription_id = ?", deliveryID, subscriptionID).
+		Updates(map[string]interface{}{
+			"status":          entity.DeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		return entity.WebhookDelivery{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return entity.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+
+	return r.GetDelivery(ctx, subscriptionID, deliveryID)
+}
+
+func subscriptionNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSubscriptionNotFound
+	}
+	return err
+}
+
+func deliveryNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDeliveryNotFound
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"simple-order-go/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnqueueDeliveries(t *testing.T) {
+	defer tearDown()
+
+	created := createSubscription(t, entity.EventOrderCreated)
+	createSubscription(t, entity.EventOrderDeleted)
+	both := createSubscription(t, entity.EventOrderCreated, entity.EventOrderDeleted)
+
+	event := entity.Event{ID: 42, Type: entity.EventOrderCreated, OrderID: 1, Data: json.RawMessage(`{"id":1}`)}
+
+	n, err := testWebhookRepo.EnqueueDeliveries(context.Background(), event)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	// The outbox may publish an event more than once.
+	n, err = testWebhookRepo.EnqueueDeliveries(context.Background(), event)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	for _, subscription := range []entity.WebhookSubscription{created, both} {
+		deliveries, err := testWebhookRepo.GetDeliveries(context.Background(), subscription.ID)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(deliveries))
+		require.Equal(t, event.ID, deliveries[0].EventID)
+		require.Equal(t, entity.DeliveryPending, deliveries[0].Status)
+
+		var sent entity.Event
+		require.NoError(t, json.Unmarshal(deliveries[0].Payload, &sent))
+		require.Equal(t, event.ID, sent.ID)
+	}
+}
+
+func TestClaimDeliveries(t *testing.T) {
+	defer tearDown()
+
+	subscription := createSubscription(t, entity.EventOrderCreated)
+	_, err := testWebhookRepo.EnqueueDeliveries(context.Background(), entity.Event{ID: 1, Type: entity.EventOrderCreated})
+	require.NoError(t, err)
+
+	claimed, err := testWebhookRepo.ClaimDeliveries(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(claimed))
+	require.Equal(t, 1, claimed[0].Attempts)
+	require.Equal(t, subscription.URL, claimed[0].Subscription.URL)
+	require.Equal(t, subscription.Secret, claimed[0].Subscription.Secret)
+
+	leased, err := testWebhookRepo.ClaimDeliveries(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, leased)
+
+	attempt := entity.WebhookDeliveryAttempt{DeliveryID: claimed[0].ID, ResponseStatus: 500, Error: "boom", DurationMs: 12}
+	err = testWebhookRepo.RecordAttempt(context.Background(), attempt, entity.DeliveryDead, time.Now())
+	require.NoError(t, err)
+
+	dead, err := testWebhookRepo.ClaimDeliveries(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, dead)
+
+	delivery, err := testWebhookRepo.GetDelivery(context.Background(), subscription.ID, claimed[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.DeliveryDead, delivery.Status)
+	require.Equal(t, "boom", delivery.LastError)
+	require.Equal(t, 1, len(delivery.Log))
+	require.Equal(t, 500, delivery.Log[0].ResponseStatus)
+
+	redelivered, err := testWebhookRepo.RedeliverDelivery(context.Background(), subscription.ID, claimed[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.DeliveryPending, redelivered.Status)
+	require.Zero(t, redelivered.Attempts)
+	require.Equal(t, 1, len(redelivered.Log))
+
+	claimed, err = testWebhookRepo.ClaimDeliveries(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(claimed))
+
+	attempt = entity.WebhookDeliveryAttempt{DeliveryID: claimed[0].ID, ResponseStatus: 200}
+	err = testWebhookRepo.RecordAttempt(context.Background(), attempt, entity.DeliverySucceeded, time.Now())
+	require.NoError(t, err)
+
+	delivery, err = testWebhookRepo.GetDelivery(context.Background(), subscription.ID, claimed[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.DeliverySucceeded, delivery.Status)
+	require.NotNil(t, delivery.DeliveredAt)
+	require.Equal(t, 2, len(delivery.Log))
+}
+
+func TestWebhookNotFound(t *testing.T) {
+	defer tearDown()
+
+	subscription := createSubscription(t, entity.EventOrderCreated)
+	missing := subscription.ID + 1
+
+	_, err := testWebhookRepo.GetSubscription(context.Background(), missing)
+	require.ErrorIs(t, err, ErrSubscriptionNotFound)
+
+	_, err = testWebhookRepo.GetDeliveries(context.Background(), missing)
+	require.ErrorIs(t, err, ErrSubscriptionNotFound)
+
+	_, err = testWebhookRepo.GetDelivery(context.Background(), subscription.ID, 1)
+	require.ErrorIs(t, err, ErrDeliveryNotFound)
+
+	_, err = testWebhookRepo.RedeliverDelivery(context.Background(), subscription.ID, 1)
+	require.ErrorIs(t, err, ErrDeliveryNotFound)
+
+	err = testWebhookRepo.DeleteSubscription(context.Background(), missing)
+	require.ErrorIs(t, err, ErrSubscriptionNotFound)
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	defer tearDown()
+
+	subscription := createSubscription(t, entity.EventOrderCreated)
+	_, err := testWebhookRepo.EnqueueDeliveries(context.Background(), entity.Event{ID: 1, Type: entity.EventOrderCreated})
+	require.NoError(t, err)
+
+	err = testWebhookRepo.DeleteSubscription(context.Background(), subscription.ID)
+	require.NoError(t, err)
+
+	subscriptions, err := testWebhookRepo.GetSubscriptions(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, subscriptions)
+
+	claimed, err := testWebhookRepo.ClaimDeliveries(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+}
+
+func createSubscription(t *testing.T, eventTypes ...entity.EventType) entity.WebhookSubscription {
+	subscription, err := testWebhookRepo.CreateSubscription(context.Background(), entity.WebhookSubscription{
+		URL:        "https://partner.example.com/hooks",
+		EventTypes: eventTypes,
+		Secret:     "0123456789abcdef0123456789abcdef",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, subscription.ID)
+	return subscription
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/internal/reqctx"
+)
+
+var ErrForbidden = apperror.Forbidden("forbidden", "not allowed to perform this action")
+
+// caller is who a service method acts for. Requests that weren't
+// authenticated, which only happens with authentication disabled, have no
+// role and aren't restricted.
+type caller struct {
+	subject string
+	role    entity.Role
+}
+
+func callerFrom(ctx context.Context) caller {
+	return caller{subject: reqctx.Actor(ctx), role: entity.Role(reqctx.Role(ctx))}
+}
+
+func (c caller) authenticated() bool {
+	return c.role != ""
+}
+
+// ownOrdersOnly reports whether the caller is limited to orders it owns.
+func (c caller) ownOrdersOnly() bool {
+	return c.authenticated() && !c.role.AtLeast(entity.RoleStaff)
+}
+
+// canAccess reports whether the caller may see and change order. Orders the
+// caller can't access are reported as not found, so customers can't probe
+// for other customers' orders.
+func (c caller) canAccess(order entity.Order) bool {
+	return !c.ownOrdersOnly() || order.Owner == c.subject
+}
+
+// requireRole returns ErrForbidden unless the caller has at least role.
+func requireRole(ctx context.Context, role entity.Role) error {
+	c := callerFrom(ctx)
+	if c.authenticated() && !c.role.AtLeast(role) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// requireTransitionRole returns ErrForbidden unless the caller may move an
+// order from one status to the other.
+func requireTransitionRole(ctx context.Context, from entity.OrderStatus, to entity.OrderStatus) error {
+	return requireRole(ctx, from.TransitionRole(to))
+}
+
+// getAccessibleOrder reads an order on behalf of the caller, reporting one it
+// can't access as not found.
+func getAccessibleOrder(ctx context.Context, orderRepo repository.IOrderRepository, orderID int64) (entity.Order, error) {
+	order, err := orderRepo.GetOrder(ctx, orderID)
+	if err != nil {
+		return entity.Order{}, err
+	}
+
+	if !callerFrom(ctx).canAccess(order) {
+		return entity.Order{}, ErrOrderNotFound
+	}
+
+	return order, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/reqctx"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func callerContext(subject string, role entity.Role) context.Context {
+	ctx := reqctx.WithActor(context.Background(), subject)
+	return reqctx.WithRole(ctx, string(role))
+}
+
+func TestOrderAccess(t *testing.T) {
+	own := entity.Order{Owner: "customer-1"}
+	other := entity.Order{Owner: "customer-2"}
+
+	testCases := []struct {
+		name      string
+		ctx       context.Context
+		own       bool
+		other     bool
+		canDelete bool
+	}{
+		{name: "Customer", ctx: callerContext("customer-1", entity.RoleCustomer), own: true},
+		{name: "Staff", ctx: callerContext("staff-1", entity.RoleStaff), own: true, other: true},
+		{name: "Admin", ctx: callerContext("admin-1", entity.RoleAdmin), own: true, other: true, canDelete: true},
+		{name: "Unauthenticated", ctx: context.Background(), own: true, other: true, canDelete: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			c := callerFrom(tc.ctx)
+			require.Equal(t, tc.own, c.canAccess(own))
+			require.Equal(t, tc.other, c.canAccess(other))
+
+			err := requireRole(tc.ctx, entity.RoleAdmin)
+			if tc.canDelete {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestTransitionAccess(t *testing.T) {
+	customer := callerContext("customer-1", entity.RoleCustomer)
+	staff := callerContext("staff-1", entity.RoleStaff)
+
+	testCases := []struct {
+		name    string
+		ctx     context.Context
+		from    entity.OrderStatus
+		to      entity.OrderStatus
+		allowed bool
+	}{
+		{name: "CustomerConfirms", ctx: customer, from: entity.OrderStatusPending, to: entity.OrderStatusConfirmed, allowed: true},
+		{name: "CustomerCancelsPending", ctx: customer, from: entity.OrderStatusPending, to: entity.OrderStatusCancelled, allowed: true},
+		{name: "CustomerCancelsConfirmed", ctx: customer, from: entity.OrderStatusConfirmed, to: entity.OrderStatusCancelled},
+		{name: "CustomerPays", ctx: customer, from: entity.OrderStatusConfirmed, to: entity.OrderStatusPaid},
+		{name: "CustomerShips", ctx: customer, from: entity.OrderStatusPaid, to: entity.OrderStatusShipped},
+		{name: "CustomerDelivers", ctx: customer, from: entity.OrderStatusShipped, to: entity.OrderStatusDelivered},
+		{name: "CustomerRefunds", ctx: customer, from: entity.OrderStatusDelivered, to: entity.OrderStatusRefunded},
+		{name: "StaffPays", ctx: staff, from: entity.OrderStatusConfirmed, to: entity.OrderStatusPaid, allowed: true},
+		{name: "StaffRefunds", ctx: staff, from: entity.OrderStatusDelivered, to: entity.OrderStatusRefunded, allowed: true},
+		{name: "AdminShips", ctx: callerContext("admin-1", entity.RoleAdmin), from: entity.OrderStatusPaid, to: entity.OrderStatusShipped, allowed: true},
+		{name: "Unauthenticated", ctx: context.Background(), from: entity.OrderStatusPaid, to: entity.OrderStatusShipped, allowed: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := requireTransitionRole(tc.ctx, tc.from, tc.to)
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestScopeQuery(t *testing.T) {
+	query := scopeQuery(callerContext("customer-1", entity.RoleCustomer), entity.OrderQuery{})
+	require.Equal(t, "customer-1", query.Filter.Owner)
+
+	query = scopeQuery(callerContext("staff-1", entity.RoleStaff), entity.OrderQuery{})
+	require.Empty(t, query.Filter.Owner)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"simple-order-go/internal/auth"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/internal/reqctx"
+)
+
+var (
+	ErrAPIKeyNotFound = repository.ErrAPIKeyNotFound
+	ErrAPIKeyRevoked  = repository.ErrAPIKeyRevoked
+)
+
+// APIKeyService manages the API keys service clients authenticate with.
+// Keys can be given any scope, so only admins may manage them.
+type APIKeyService struct {
+	apiKeyRepo repository.IAPIKeyRepository
+}
+
+type IAPIKeyService interface {
+	CreateKey(ctx context.Context, name string, scopes []entity.Scope) (entity.APIKeyViewModel, error)
+	GetKeys(ctx context.Context) ([]entity.APIKeyViewModel, error)
+	RotateKey(ctx context.Context, keyID int64) (entity.APIKeyViewModel, error)
+	RevokeKey(ctx context.Context, keyID int64) error
+}
+
+func NewAPIKeyService(apiKeyRepo repository.IAPIKeyRepository) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo}
+}
+
+// CreateKey generates a key for the current caller. The result is the only
+// place the key is returned.
+func (s *APIKeyService) CreateKey(ctx context.Context, name string, scopes []entity.Scope) (entity.APIKeyViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return entity.APIKeyViewModel{}, err
+	}
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return entity.APIKeyViewModel{}, err
+	}
+
+	created, err := s.apiKeyRepo.CreateKey(ctx, entity.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    scopes,
+		CreatedBy: reqctx.Actor(ctx),
+	})
+	if err != nil {
+		return entity.APIKeyViewModel{}, err
+	}
+
+	result := created.ToViewModel()
+	result.Key = key
+	return result, nil
+}
+
+func (s *APIKeyService) GetKeys(ctx context.Context) ([]entity.APIKeyViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return []entity.APIKeyViewModel{}, err
+	}
+
+	result, err := s.apiKeyRepo.GetKeys(ctx)
+	if err != nil {
+		return []entity.APIKeyViewModel{}, err
+	}
+
+	keys := make([]entity.APIKeyViewModel, len(result))
+	for i, key := range result {
+		keys[i] = key.ToViewModel()
+	}
+
+	return keys, nil
+}
+
+// RotateKey replaces a key with a new one, keeping its name and scopes. The
+// old key stops working at once.
+func (s *APIKeyService) RotateKey(ctx context.Context, keyID int64) (entity.APIKeyViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return entity.APIKeyViewModel{}, err
+	}
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return entity.APIKeyViewModel{}, err
+	}
+
+	rotated, err := s.apiKeyRepo.RotateKey(ctx, keyID, prefix, hash)
+	if err != nil {
+		return entity.APIKeyViewModel{}, err
+	}
+
+	result := rotated.ToViewModel()
+	result.Key = key
+	return result, nil
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, keyID int64) error {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return err
+	}
+
+	return s.apiKeyRepo.RevokeKey(ctx, keyID)
+}
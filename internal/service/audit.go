@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/internal/reqctx"
+	"sort"
+)
+
+// anonymousActor is recorded for changes made by requests without an
+// identity.
+const anonymousActor = "anonymous"
+
+// recordAudit appends an audit entry describing how an order changed from
+// before to after. A nil before means the order appeared, a nil after that it
+// went away. It should run in the transaction that made the change.
+func recordAudit(
+	ctx context.Context,
+	auditRepo repository.IAuditRepository,
+	orderID int64,
+	action entity.AuditAction,
+	before, after *entity.OrderViewModel,
+) error {
+	changes, err := diffOrders(before, after)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	actor := reqctx.Actor(ctx)
+	if actor == "" {
+		actor = anonymousActor
+	}
+
+	return auditRepo.CreateEntry(ctx, entity.AuditEntry{
+		OrderID:   orderID,
+		Action:    action,
+		Actor:     actor,
+		RequestID: reqctx.RequestID(ctx),
+		Changes:   data,
+	})
+}
+
+// diffOrders compares the JSON representations of two orders and lists the
+// values that differ. Items are matched by ID, so removing one item doesn't
+// show every item after it as changed.
+func diffOrders(before, after *entity.OrderViewModel) ([]entity.AuditChange, error) {
+	from, err := jsonValue(before)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := jsonValue(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []entity.AuditChange{}
+	diffValues("", from, to, &changes)
+	return changes, nil
+}
+
+func jsonValue(order *entity.OrderViewModel) (interface{}, error) {
+	if order == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err = decoder.Decode(&value)
+	return value, err
+}
+
+// diffValues appends the differences between two decoded JSON values to
+// changes. Objects and arrays are compared element by element, with a
+// missing side treated as empty. Arrays of objects that all have an ID are
+// compared by ID, and their elements addressed as e.g. "items[id=12]";
+// other arrays are compared by position.
+func diffValues(path string, from, to interface{}, changes *[]entity.AuditChange) {
+	fromObject, fromIsObject := from.(map[string]interface{})
+	toObject, toIsObject := to.(map[string]interface{})
+	if (fromIsObject || from == nil) && (toIsObject || to == nil) && (fromIsObject || toIsObject) {
+		keys := make([]string, 0, len(fromObject)+len(toObject))
+		for key := range fromObject {
+			keys = append(keys, key)
+		}
+		for key := range toObject {
+			if _, ok := fromObject[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			next := key
+			if path != "" {
+				next = path + "." + key
+			}
+			diffValues(next, fromObject[key], toObject[key], changes)
+		}
+		return
+	}
+
+	fromArray, fromIsArray := from.([]interface{})
+	toArray, toIsArray := to.([]interface{})
+	if (fromIsArray || from == nil) && (toIsArray || to == nil) && (fromIsArray || toIsArray) {
+		fromIDs, fromByID, fromKeyed := byID(fromArray)
+		toIDs, toByID, toKeyed := byID(toArray)
+		if fromKeyed && toKeyed {
+			for _, id := range fromIDs {
+				diffValues(fmt.Sprintf("%s[id=%s]", path, id), fromByID[id], toByID[id], changes)
+			}
+			for _, id := range toIDs {
+				if _, ok := fromByID[id]; !ok {
+					diffValues(fmt.Sprintf("%s[id=%s]", path, id), nil, toByID[id], changes)
+				}
+			}
+			return
+		}
+
+		n := len(fromArray)
+		if len(toArray) > n {
+			n = len(toArray)
+		}
+
+		for i := 0; i < n; i++ {
+			var fromElem, toElem interface{}
+			if i < len(fromArray) {
+				fromElem = fromArray[i]
+			}
+			if i < len(toArray) {
+				toElem = toArray[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), fromElem, toElem, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, entity.AuditChange{Path: path, From: from, To: to})
+	}
+}
+
+// byID indexes the objects in array by their "id", returning the IDs in
+// order. It reports false if an element isn't an object with a nonzero,
+// unique ID.
+func byID(array []interface{}) ([]string, map[string]interface{}, bool) {
+	ids := make([]string, 0, len(array))
+	elems := make(map[string]interface{}, len(array))
+	for _, elem := range array {
+		object, ok := elem.(map[string]interface{})
+		if !ok || object["id"] == nil {
+			return nil, nil, false
+		}
+
+		id := fmt.Sprint(object["id"])
+		if _, ok := elems[id]; ok || id == "0" {
+			return nil, nil, false
+		}
+		ids = append(ids, id)
+		elems[id] = elem
+	}
+	return ids, elems, true
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"simple-order-go/internal/entity"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func auditOrder(items ...entity.ItemViewModel) *entity.OrderViewModel {
+	return &entity.OrderViewModel{ID: 1, CustomerName: "Alice", Items: items}
+}
+
+func changedPaths(changes []entity.AuditChange) []string {
+	paths := []string{}
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+func TestDiffOrders(t *testing.T) {
+	book := entity.ItemViewModel{ID: 10, Name: "Book", Quantity: 1}
+	pen := entity.ItemViewModel{ID: 11, Name: "Pen", Quantity: 2}
+	ink := entity.ItemViewModel{ID: 12, Name: "Ink", Quantity: 3}
+
+	morePens := pen
+	morePens.Quantity = 5
+
+	renamed := auditOrder(book)
+	renamed.CustomerName = "Bob"
+
+	testCases := []struct {
+		name   string
+		before *entity.OrderViewModel
+		after  *entity.OrderViewModel
+		check  func(t *testing.T, changes []entity.AuditChange)
+	}{
+		{
+			name:   "RemoveFirstItem",
+			before: auditOrder(book, pen, ink),
+			after:  auditOrder(pen, ink),
+			check: func(t *testing.T, changes []entity.AuditChange) {
+				require.NotEmpty(t, changes)
+				for _, change := range changes {
+					require.True(t, strings.HasPrefix(change.Path, "items[id=10]."), change.Path)
+					require.Nil(t, change.To)
+				}
+			},
+		},
+		{
+			name:   "AddItem",
+			before: auditOrder(book),
+			after:  auditOrder(book, pen),
+			check: func(t *testing.T, changes []entity.AuditChange) {
+				require.NotEmpty(t, changes)
+				for _, change := range changes {
+					require.True(t, strings.HasPrefix(change.Path, "items[id=11]."), change.Path)
+					require.Nil(t, change.From)
+				}
+			},
+		},
+		{
+			name:   "ChangeItem",
+			before: auditOrder(book, pen),
+			after:  auditOrder(book, morePens),
+			check: func(t *testing.T, changes []entity.AuditChange) {
+				require.Equal(t, []string{"items[id=11].quantity"}, changedPaths(changes))
+			},
+		},
+		{
+			name:   "ReorderItems",
+			before: auditOrder(book, pen),
+			after:  auditOrder(pen, book),
+			check: func(t *testing.T, changes []entity.AuditChange) {
+				require.Empty(t, changes)
+			},
+		},
+		{
+			name:   "ChangeField",
+			before: auditOrder(book),
+			after:  renamed,
+			check: func(t *testing.T, changes []entity.AuditChange) {
+				require.Equal(t, []string{"customer_name"}, changedPaths(changes))
+			},
+		},
+		{
+			name:   "ItemsWithoutIDs",
+			before: auditOrder(entity.ItemViewModel{Name: "Book"}, entity.ItemViewModel{Name: "Pen"}),
+			after:  auditOrder(entity.ItemViewModel{Name: "Pen"}),
+			check: func(t *testing.T, changes []entity.AuditChange) {
+				paths := changedPaths(changes)
+				require.Contains(t, paths, "items[0].name")
+				require.Contains(t, paths, "items[1].name")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := diffOrders(tc.before, tc.after)
+			require.NoError(t, err)
+			tc.check(t, changes)
+		})
+	}
+}
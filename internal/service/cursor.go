@@ -0,0 +1,100 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidCursor = apperror.Validation("invalid_cursor", "invalid cursor")
+
+// cursorPayload is the wire form of a cursor. It records the sort it was
+// issued for so that it can't be replayed against a different ordering.
+type cursorPayload struct {
+	Field entity.OrderSortField `json:"f"`
+	Desc  bool                  `json:"d,omitempty"`
+	Value string                `json:"v"`
+	ID    int64                 `json:"id"`
+}
+
+func encodeCursor(sort entity.OrderSort, order entity.Order) string {
+	p := cursorPayload{Field: sort.Field, Desc: sort.Desc, ID: order.ID}
+
+	switch sort.Field {
+	case entity.OrderSortCustomerName:
+		p.Value = order.CustomerName
+	case entity.OrderSortOrderedAt:
+		p.Value = order.OrderedAt.Format(time.RFC3339Nano)
+	case entity.OrderSortItemDescription:
+		p.Value = minItemDescription(order.Items)
+	case entity.OrderSortItemQuantity:
+		p.Value = strconv.FormatInt(int64(maxItemQuantity(order.Items)), 10)
+	default:
+		p.Value = order.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	b, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(sort entity.OrderSort, s string) (*entity.OrderCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(b, &p); err != nil || p.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	if p.Field != sort.Field || p.Desc != sort.Desc {
+		return nil, ErrInvalidCursor
+	}
+
+	var value interface{}
+	switch p.Field {
+	case entity.OrderSortCustomerName, entity.OrderSortItemDescription:
+		value = p.Value
+	case entity.OrderSortItemQuantity:
+		value, err = strconv.ParseInt(p.Value, 10, 32)
+	default:
+		value, err = time.Parse(time.RFC3339Nano, p.Value)
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &entity.OrderCursor{Value: value, ID: p.ID}, nil
+}
+
+func minItemDescription(items []entity.Item) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	min := items[0].Description
+	for _, item := range items[1:] {
+		if item.Description < min {
+			min = item.Description
+		}
+	}
+	return min
+}
+
+func maxItemQuantity(items []entity.Item) int32 {
+	var max int32
+	for _, item := range items {
+		if item.Quantity > max {
+			max = item.Quantity
+		}
+	}
+	return max
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"encoding/base64"
+	"simple-order-go/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC)
+	orderedAt := time.Date(2024, 2, 28, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+	order := entity.Order{
+		ID:           42,
+		CustomerName: "Alice",
+		OrderedAt:    orderedAt,
+		CreatedAt:    createdAt,
+		Items: []entity.Item{
+			{Description: "pens", Quantity: 3},
+			{Description: "books", Quantity: 7},
+		},
+	}
+
+	testCases := []struct {
+		name  string
+		sort  entity.OrderSort
+		check func(t *testing.T, value interface{})
+	}{
+		{
+			name: "CreatedAt",
+			sort: entity.OrderSort{Field: entity.OrderSortCreatedAt},
+			check: func(t *testing.T, value interface{}) {
+				require.True(t, createdAt.Equal(value.(time.Time)))
+			},
+		},
+		{
+			name: "CreatedAtDesc",
+			sort: entity.OrderSort{Field: entity.OrderSortCreatedAt, Desc: true},
+			check: func(t *testing.T, value interface{}) {
+				require.True(t, createdAt.Equal(value.(time.Time)))
+			},
+		},
+		{
+			name: "CustomerName",
+			sort: entity.OrderSort{Field: entity.OrderSortCustomerName},
+			check: func(t *testing.T, value interface{}) {
+				require.Equal(t, "Alice", value)
+			},
+		},
+		{
+			name: "OrderedAt",
+			sort: entity.OrderSort{Field: entity.OrderSortOrderedAt, Desc: true},
+			check: func(t *testing.T, value interface{}) {
+				require.True(t, orderedAt.Equal(value.(time.Time)))
+			},
+		},
+		{
+			name: "ItemDescription",
+			sort: entity.OrderSort{Field: entity.OrderSortItemDescription},
+			check: func(t *testing.T, value interface{}) {
+				require.Equal(t, "books", value)
+			},
+		},
+		{
+			name: "ItemQuantity",
+			sort: entity.OrderSort{Field: entity.OrderSortItemQuantity},
+			check: func(t *testing.T, value interface{}) {
+				require.Equal(t, int64(7), value)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cursor, err := decodeCursor(tc.sort, encodeCursor(tc.sort, order))
+			require.NoError(t, err)
+			require.Equal(t, order.ID, cursor.ID)
+			tc.check(t, cursor.Value)
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	sort := entity.OrderSort{Field: entity.OrderSortCustomerName}
+	valid := encodeCursor(sort, entity.Order{ID: 1, CustomerName: "Alice"})
+	encode := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(payload))
+	}
+
+	testCases := []struct {
+		name   string
+		sort   entity.OrderSort
+		cursor string
+		err    error
+	}{
+		{name: "Empty", sort: sort, cursor: ""},
+		{name: "Valid", sort: sort, cursor: valid},
+		{name: "NotBase64", sort: sort, cursor: "not base64!", err: ErrInvalidCursor},
+		{name: "PaddedBase64", sort: sort, cursor: valid + "==", err: ErrInvalidCursor},
+		{name: "Truncated", sort: sort, cursor: valid[:len(valid)-4], err: ErrInvalidCursor},
+		{name: "NotJSON", sort: sort, cursor: encode("alice"), err: ErrInvalidCursor},
+		{name: "MissingID", sort: sort, cursor: encode(`{"f":"customer_name","v":"Alice"}`), err: ErrInvalidCursor},
+		{name: "NegativeID", sort: sort, cursor: encode(`{"f":"customer_name","v":"Alice","id":-1}`), err: ErrInvalidCursor},
+		{
+			name:   "OtherField",
+			sort:   entity.OrderSort{Field: entity.OrderSortCreatedAt},
+			cursor: valid,
+			err:    ErrInvalidCursor,
+		},
+		{
+			name:   "OtherDirection",
+			sort:   entity.OrderSort{Field: entity.OrderSortCustomerName, Desc: true},
+			cursor: valid,
+			err:    ErrInvalidCursor,
+		},
+		{
+			name:   "TamperedTime",
+			sort:   entity.OrderSort{Field: entity.OrderSortCreatedAt},
+			cursor: encode(`{"f":"created_at","v":"yesterday","id":1}`),
+			err:    ErrInvalidCursor,
+		},
+		{
+			name:   "TamperedQuantity",
+			sort:   entity.OrderSort{Field: entity.OrderSortItemQuantity},
+			cursor: encode(`{"f":"item_quantity","v":"99999999999","id":1}`),
+			err:    ErrInvalidCursor,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cursor, err := decodeCursor(tc.sort, tc.cursor)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.Nil(t, cursor)
+				return
+			}
+			require.NoError(t, err)
+			if tc.cursor == "" {
+				require.Nil(t, cursor)
+			} else {
+				require.NotNil(t, cursor)
+			}
+		})
+	}
+}
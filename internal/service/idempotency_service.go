@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/pkg/config"
+	"time"
+)
+
+const (
+	defaultIdempotencyTTL         = 24 * time.Hour
+	defaultIdempotencyLockTimeout = time.Minute
+
+	lockTokenBytes = 16
+)
+
+var (
+	ErrIdempotencyKeyReused = apperror.Unprocessable(
+		"idempotency_key_reused", "idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = apperror.Conflict(
+		"idempotency_key_in_progress", "a request with this idempotency key is still being processed")
+)
+
+type IdempotencyService struct {
+	idempotencyRepo repository.IIdempotencyRepository
+	ttl             time.Duration
+	lockTimeout     time.Duration
+}
+
+type IIdempotencyService interface {
+	Begin(ctx context.Context, owner string, key string, fingerprint string) (*entity.IdempotencyKey, error)
+	Complete(ctx context.Context, owner string, key string, lockToken string, statusCode int, responseBody []byte) error
+	Release(ctx context.Context, owner string, key string, lockToken string) error
+}
+
+func NewIdempotencyService(idempotencyRepo repository.IIdempotencyRepository, cfg config.Idempotency) *IdempotencyService {
+	s := &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             cfg.TTL,
+		lockTimeout:     cfg.LockTimeout,
+	}
+	if s.ttl <= 0 {
+		s.ttl = defaultIdempotencyTTL
+	}
+	if s.lockTimeout <= 0 {
+		s.lockTimeout = defaultIdempotencyLockTimeout
+	}
+	return s
+}
+
+// Begin claims owner's key for a request. It returns the stored record if it
+// was already completed and its response should be replayed; otherwise the
+// request should be processed, and the returned record holds the LockToken
+// to complete or release the key with. Owners only ever see their own keys. A
+// key stays locked to its request for the lock timeout; if the request
+// hasn't finished by then, a retry takes the key over and the first request
+// can no longer complete or release it.
+func (s *IdempotencyService) Begin(ctx context.Context, owner string, key string, fingerprint string) (*entity.IdempotencyKey, error) {
+	lockToken, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record, created, err := s.idempotencyRepo.CreateKey(ctx, entity.IdempotencyKey{
+		Owner:       owner,
+		Key:         key,
+		Fingerprint: fingerprint,
+		LockedUntil: now.Add(s.lockTimeout),
+		LockToken:   lockToken,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		return &record, nil
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	if !record.Completed() {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response to the request holding lockToken.
+func (s *IdempotencyService) Complete(ctx context.Context, owner string, key string, lockToken string, statusCode int, responseBody []byte) error {
+	return s.idempotencyRepo.CompleteKey(ctx, owner, key, lockToken, statusCode, responseBody)
+}
+
+// Release forgets a key whose request didn't succeed so that it can be
+// retried.
+func (s *IdempotencyService) Release(ctx context.Context, owner string, key string, lockToken string) error {
+	return s.idempotencyRepo.DeleteKey(ctx, owner, key, lockToken)
+}
+
+func newLockToken() (string, error) {
+	b := make([]byte, lockTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+)
+
+var ErrItemNotFound = repository.ErrItemNotFound
+
+type ItemService struct {
+	orderRepo  repository.IOrderRepository
+	itemRepo   repository.IItemRepository
+	auditRepo  repository.IAuditRepository
+	transactor repository.ITransactor
+}
+
+type IItemService interface {
+	GetItems(ctx context.Context, orderID int64) ([]entity.ItemViewModel, error)
+	GetItem(ctx context.Context, orderID int64, itemID int64) (entity.ItemViewModel, error)
+	CreateItem(ctx context.Context, orderID int64, item entity.ItemViewModel) (entity.ItemViewModel, error)
+	UpdateItem(ctx context.Context, orderID int64, itemID int64, patch entity.ItemPatch) (entity.ItemViewModel, error)
+	DeleteItem(ctx context.Context, orderID int64, itemID int64) error
+}
+
+func NewItemService(
+	orderRepo repository.IOrderRepository,
+	itemRepo repository.IItemRepository,
+	auditRepo repository.IAuditRepository,
+	transactor repository.ITransactor,
+) *ItemService {
+	return &ItemService{orderRepo: orderRepo, itemRepo: itemRepo, auditRepo: auditRepo, transactor: transactor}
+}
+
+func (s *ItemService) GetItems(ctx context.Context, orderID int64) ([]entity.ItemViewModel, error) {
+	order, err := getAccessibleOrder(ctx, s.orderRepo, orderID)
+	if err != nil {
+		return []entity.ItemViewModel{}, err
+	}
+
+	return order.ToViewModel().Items, nil
+}
+
+func (s *ItemService) GetItem(ctx context.Context, orderID int64, itemID int64) (entity.ItemViewModel, error) {
+	order, err := getAccessibleOrder(ctx, s.orderRepo, orderID)
+	if err != nil {
+		return entity.ItemViewModel{}, err
+	}
+
+	i := findItem(order.Items, itemID)
+	if i < 0 {
+		return entity.ItemViewModel{}, ErrItemNotFound
+	}
+
+	return order.Items[i].ToViewModel(), nil
+}
+
+func (s *ItemService) CreateItem(ctx context.Context, orderID int64, item entity.ItemViewModel) (entity.ItemViewModel, error) {
+	var created entity.Item
+	err := s.changeOrder(ctx, orderID, entity.AuditActionItemAdded, func(ctx context.Context, order entity.Order) error {
+		item.ID = 0
+		order.Items = append(order.Items, item.ToEntity(orderID))
+		if err := priceOrder(&order); err != nil {
+			return err
+		}
+
+		result, err := s.itemRepo.CreateItem(ctx, order, order.Items[len(order.Items)-1])
+		if err != nil {
+			return concurrentUpdate(err)
+		}
+
+		created = result
+		return nil
+	})
+	if err != nil {
+		return entity.ItemViewModel{}, err
+	}
+
+	return created.ToViewModel(), nil
+}
+
+func (s *ItemService) UpdateItem(ctx context.Context, orderID int64, itemID int64, patch entity.ItemPatch) (entity.ItemViewModel, error) {
+	var updated entity.Item
+	err := s.changeOrder(ctx, orderID, entity.AuditActionItemUpdated, func(ctx context.Context, order entity.Order) error {
+		i := findItem(order.Items, itemID)
+		if i < 0 {
+			return ErrItemNotFound
+		}
+
+		patch.Apply(&order.Items[i])
+		if err := priceOrder(&order); err != nil {
+			return err
+		}
+
+		result, err := s.itemRepo.UpdateItem(ctx, order, order.Items[i])
+		if err != nil {
+			return concurrentUpdate(err)
+		}
+
+		updated = result
+		return nil
+	})
+	if err != nil {
+		return entity.ItemViewModel{}, err
+	}
+
+	return updated.ToViewModel(), nil
+}
+
+func (s *ItemService) DeleteItem(ctx context.Context, orderID int64, itemID int64) error {
+	return s.changeOrder(ctx, orderID, entity.AuditActionItemDeleted, func(ctx context.Context, order entity.Order) error {
+		i := findItem(order.Items, itemID)
+		if i < 0 {
+			return ErrItemNotFound
+		}
+
+		order.Items = append(order.Items[:i], order.Items[i+1:]...)
+		if err := priceOrder(&order); err != nil {
+			return err
+		}
+
+		return concurrentUpdate(s.itemRepo.DeleteItem(ctx, order, itemID))
+	})
+}
+
+// changeOrder runs change on the current state of an order in a transaction
+// and records the change in the order's audit trail.
+func (s *ItemService) changeOrder(
+	ctx context.Context,
+	orderID int64,
+	action entity.AuditAction,
+	change func(ctx context.Context, order entity.Order) error,
+) error {
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		order, err := getAccessibleOrder(ctx, s.orderRepo, orderID)
+		if err != nil {
+			return err
+		}
+		before := order.ToViewModel()
+
+		if err := change(ctx, order); err != nil {
+			return err
+		}
+
+		result, err := s.orderRepo.GetOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		after := result.ToViewModel()
+
+		return recordAudit(ctx, s.auditRepo, orderID, action, &before, &after)
+	})
+}
+
+func findItem(items []entity.Item, itemID int64) int {
+	for i, item := range items {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Item requests carry no If-Match, so a version clash means another request
+// changed the order in the meantime rather than that the client's copy was
+// stale.
+func concurrentUpdate(err error) error {
+	if errors.Is(err, ErrVersionMismatch) {
+		return ErrConcurrentUpdate
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: simple-order-go/internal/service (interfaces: IAPIKeyService)
+
+// Package mockService is a generated GoMock package.
+package mockService
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "simple-order-go/internal/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIAPIKeyService is a mock of IAPIKeyService interface.
+type MockIAPIKeyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAPIKeyServiceMockRecorder
+}
+
+// MockIAPIKeyServiceMockRecorder is the mock recorder for MockIAPIKeyService.
+type MockIAPIKeyServiceMockRecorder struct {
+	mock *MockIAPIKeyService
+}
+
+// NewMockIAPIKeyService creates a new mock instance.
+func NewMockIAPIKeyService(ctrl *gomock.Controller) *MockIAPIKeyService {
+	mock := &MockIAPIKeyService{ctrl: ctrl}
+	mock.recorder = &MockIAPIKeyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAPIKeyService) EXPECT() *MockIAPIKeyServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateKey mocks base method.
+func (m *MockIAPIKeyService) CreateKey(arg0 context.Context, arg1 string, arg2 []entity.Scope) (entity.APIKeyViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.APIKeyViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *MockIAPIKeyServiceMockRecorder) CreateKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*MockIAPIKeyService)(nil).CreateKey), arg0, arg1, arg2)
+}
+
+// GetKeys mocks base method.
+func (m *MockIAPIKeyService) GetKeys(arg0 context.Context) ([]entity.APIKeyViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeys", arg0)
+	ret0, _ := ret[0].([]entity.APIKeyViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeys indicates an expected call of GetKeys.
+func (mr *MockIAPIKeyServiceMockRecorder) GetKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeys", reflect.TypeOf((*MockIAPIKeyService)(nil).GetKeys), arg0)
+}
+
+// RevokeKey mocks base method.
+func (m *MockIAPIKeyService) RevokeKey(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *MockIAPIKeyServiceMockRecorder) RevokeKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*MockIAPIKeyService)(nil).RevokeKey), arg0, arg1)
+}
+
+// RotateKey mocks base method.
+func (m *MockIAPIKeyService) RotateKey(arg0 context.Context, arg1 int64) (entity.APIKeyViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateKey", arg0, arg1)
+	ret0, _ := ret[0].(entity.APIKeyViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateKey indicates an expected call of RotateKey.
+func (mr *MockIAPIKeyServiceMockRecorder) RotateKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateKey", reflect.TypeOf((*MockIAPIKeyService)(nil).RotateKey), arg0, arg1)
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: simple-order-go/internal/service (interfaces: IItemService)
+
+// Package mockService is a generated GoMock package.
+package mockService
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "simple-order-go/internal/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIItemService is a mock of IItemService interface.
+type MockIItemService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIItemServiceMockRecorder
+}
+
+// MockIItemServiceMockRecorder is the mock recorder for MockIItemService.
+type MockIItemServiceMockRecorder struct {
+	mock *MockIItemService
+}
+
+// NewMockIItemService creates a new mock instance.
+func NewMockIItemService(ctrl *gomock.Controller) *MockIItemService {
+	mock := &MockIItemService{ctrl: ctrl}
+	mock.recorder = &MockIItemServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIItemService) EXPECT() *MockIItemServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateItem mocks base method.
+func (m *MockIItemService) CreateItem(arg0 context.Context, arg1 int64, arg2 entity.ItemViewModel) (entity.ItemViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateItem", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.ItemViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateItem indicates an expected call of CreateItem.
+func (mr *MockIItemServiceMockRecorder) CreateItem(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItem", reflect.TypeOf((*MockIItemService)(nil).CreateItem), arg0, arg1, arg2)
+}
+
+// DeleteItem mocks base method.
+func (m *MockIItemService) DeleteItem(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteItem", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteItem indicates an expected call of DeleteItem.
+func (mr *MockIItemServiceMockRecorder) DeleteItem(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockIItemService)(nil).DeleteItem), arg0, arg1, arg2)
+}
+
+// GetItem mocks base method.
+func (m *MockIItemService) GetItem(arg0 context.Context, arg1, arg2 int64) (entity.ItemViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItem", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.ItemViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItem indicates an expected call of GetItem.
+func (mr *MockIItemServiceMockRecorder) GetItem(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockIItemService)(nil).GetItem), arg0, arg1, arg2)
+}
+
+// GetItems mocks base method.
+func (m *MockIItemService) GetItems(arg0 context.Context, arg1 int64) ([]entity.ItemViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItems", arg0, arg1)
+	ret0, _ := ret[0].([]entity.ItemViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItems indicates an expected call of GetItems.
+func (mr *MockIItemServiceMockRecorder) GetItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItems", reflect.TypeOf((*MockIItemService)(nil).GetItems), arg0, arg1)
+}
+
+// UpdateItem mocks base method.
+func (m *MockIItemService) UpdateItem(arg0 context.Context, arg1, arg2 int64, arg3 entity.ItemPatch) (entity.ItemViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateItem", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(entity.ItemViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateItem indicates an expected call of UpdateItem.
+func (mr *MockIItemServiceMockRecorder) UpdateItem(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockIItemService)(nil).UpdateItem), arg0, arg1, arg2, arg3)
+}
@@ -5,8 +5,10 @@
 package mockService
 
 import (
+	context "context"
 	reflect "reflect"
 	entity "simple-order-go/internal/entity"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,74 +37,149 @@ func (m *MockIOrderService) EXPECT() *MockIOrderServiceMockRecorder {
 }
 
 // CreateOrder mocks base method.
-func (m *MockIOrderService) CreateOrder(arg0 entity.OrderViewModel) (entity.OrderViewModel, error) {
+func (m *MockIOrderService) CreateOrder(arg0 context.Context, arg1 entity.OrderViewModel) (entity.OrderViewModel, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateOrder", arg0)
+	ret := m.ctrl.Call(m, "CreateOrder", arg0, arg1)
 	ret0, _ := ret[0].(entity.OrderViewModel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateOrder indicates an expected call of CreateOrder.
-func (mr *MockIOrderServiceMockRecorder) CreateOrder(arg0 interface{}) *gomock.Call {
+func (mr *MockIOrderServiceMockRecorder) CreateOrder(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockIOrderService)(nil).CreateOrder), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockIOrderService)(nil).CreateOrder), arg0, arg1)
 }
 
 // DeleteOrder mocks base method.
-func (m *MockIOrderService) DeleteOrder(arg0 int64) error {
+func (m *MockIOrderService) DeleteOrder(arg0 context.Context, arg1, arg2 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteOrder", arg0)
+	ret := m.ctrl.Call(m, "DeleteOrder", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteOrder indicates an expected call of DeleteOrder.
-func (mr *MockIOrderServiceMockRecorder) DeleteOrder(arg0 interface{}) *gomock.Call {
+func (mr *MockIOrderServiceMockRecorder) DeleteOrder(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrder", reflect.TypeOf((*MockIOrderService)(nil).DeleteOrder), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrder", reflect.TypeOf((*MockIOrderService)(nil).DeleteOrder), arg0, arg1, arg2)
 }
 
-// GetAllOrders mocks base method.
-func (m *MockIOrderService) GetAllOrders() ([]entity.OrderViewModel, error) {
+// GetOrder mocks base method.
+func (m *MockIOrderService) GetOrder(arg0 context.Context, arg1 int64) (entity.OrderViewModel, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllOrders")
-	ret0, _ := ret[0].([]entity.OrderViewModel)
+	ret := m.ctrl.Call(m, "GetOrder", arg0, arg1)
+	ret0, _ := ret[0].(entity.OrderViewModel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllOrders indicates an expected call of GetAllOrders.
-func (mr *MockIOrderServiceMockRecorder) GetAllOrders() *gomock.Call {
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockIOrderServiceMockRecorder) GetOrder(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllOrders", reflect.TypeOf((*MockIOrderService)(nil).GetAllOrders))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockIOrderService)(nil).GetOrder), arg0, arg1)
 }
 
-// GetOrder mocks base method.
-func (m *MockIOrderService) GetOrder(arg0 int64) (entity.OrderViewModel, error) {
+// GetOrderHistory mocks base method.
+func (m *MockIOrderService) GetOrderHistory(arg0 context.Context, arg1 int64) ([]entity.AuditEntryViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderHistory", arg0, arg1)
+	ret0, _ := ret[0].([]entity.AuditEntryViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderHistory indicates an expected call of GetOrderHistory.
+func (mr *MockIOrderServiceMockRecorder) GetOrderHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderHistory", reflect.TypeOf((*MockIOrderService)(nil).GetOrderHistory), arg0, arg1)
+}
+
+// GetOrderTransitions mocks base method.
+func (m *MockIOrderService) GetOrderTransitions(arg0 context.Context, arg1 int64) ([]entity.OrderStatusTransitionViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderTransitions", arg0, arg1)
+	ret0, _ := ret[0].([]entity.OrderStatusTransitionViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderTransitions indicates an expected call of GetOrderTransitions.
+func (mr *MockIOrderServiceMockRecorder) GetOrderTransitions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderTransitions", reflect.TypeOf((*MockIOrderService)(nil).GetOrderTransitions), arg0, arg1)
+}
+
+// GetOrdersPage mocks base method.
+func (m *MockIOrderService) GetOrdersPage(arg0 context.Context, arg1 entity.OrderQuery, arg2 entity.PageRequest) (entity.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersPage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersPage indicates an expected call of GetOrdersPage.
+func (mr *MockIOrderServiceMockRecorder) GetOrdersPage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersPage", reflect.TypeOf((*MockIOrderService)(nil).GetOrdersPage), arg0, arg1, arg2)
+}
+
+// PurgeOrders mocks base method.
+func (m *MockIOrderService) PurgeOrders(arg0 context.Context, arg1 time.Duration) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrder", arg0)
+	ret := m.ctrl.Call(m, "PurgeOrders", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeOrders indicates an expected call of PurgeOrders.
+func (mr *MockIOrderServiceMockRecorder) PurgeOrders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeOrders", reflect.TypeOf((*MockIOrderService)(nil).PurgeOrders), arg0, arg1)
+}
+
+// RestoreOrder mocks base method.
+func (m *MockIOrderService) RestoreOrder(arg0 context.Context, arg1 int64) (entity.OrderViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreOrder", arg0, arg1)
 	ret0, _ := ret[0].(entity.OrderViewModel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOrder indicates an expected call of GetOrder.
-func (mr *MockIOrderServiceMockRecorder) GetOrder(arg0 interface{}) *gomock.Call {
+// RestoreOrder indicates an expected call of RestoreOrder.
+func (mr *MockIOrderServiceMockRecorder) RestoreOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreOrder", reflect.TypeOf((*MockIOrderService)(nil).RestoreOrder), arg0, arg1)
+}
+
+// TransitionOrder mocks base method.
+func (m *MockIOrderService) TransitionOrder(arg0 context.Context, arg1 int64, arg2 entity.OrderStatus) (entity.OrderViewModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionOrder", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.OrderViewModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransitionOrder indicates an expected call of TransitionOrder.
+func (mr *MockIOrderServiceMockRecorder) TransitionOrder(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockIOrderService)(nil).GetOrder), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionOrder", reflect.TypeOf((*MockIOrderService)(nil).TransitionOrder), arg0, arg1, arg2)
 }
 
 // UpdateOrder mocks base method.
-func (m *MockIOrderService) UpdateOrder(arg0 entity.OrderViewModel) error {
+func (m *MockIOrderService) UpdateOrder(arg0 context.Context, arg1 entity.OrderViewModel) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOrder", arg0)
+	ret := m.ctrl.Call(m, "UpdateOrder", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateOrder indicates an expected call of UpdateOrder.
-func (mr *MockIOrderServiceMockRecorder) UpdateOrder(arg0 interface{}) *gomock.Call {
+func (mr *MockIOrderServiceMockRecorder) UpdateOrder(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockIOrderService)(nil).UpdateOrder), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockIOrderService)(nil).UpdateOrder), arg0, arg1)
 }
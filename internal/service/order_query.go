@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+)
+
+var ErrInvalidQuery = apperror.Validation("invalid_query", "invalid order query")
+
+func normalizeQuery(query entity.OrderQuery) (entity.OrderQuery, error) {
+	if query.Sort.Field == "" {
+		query.Sort.Field = entity.OrderSortCreatedAt
+	}
+	if !query.Sort.Field.IsValid() {
+		return query, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, query.Sort.Field)
+	}
+
+	f := query.Filter
+	if f.OrderedFrom != nil && f.OrderedTo != nil && f.OrderedFrom.After(*f.OrderedTo) {
+		return query, fmt.Errorf("%w: ordered_from is after ordered_to", ErrInvalidQuery)
+	}
+	if f.MinItemQuantity < 0 {
+		return query, fmt.Errorf("%w: min_quantity must not be negative", ErrInvalidQuery)
+	}
+
+	return query, nil
+}
+
+// scopeQuery limits a listing to the orders the caller may see.
+func scopeQuery(ctx context.Context, query entity.OrderQuery) entity.OrderQuery {
+	if c := callerFrom(ctx); c.ownOrdersOnly() {
+		query.Filter.Owner = c.subject
+	}
+	return query
+}
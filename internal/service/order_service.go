@@ -1,38 +1,93 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"simple-order-go/internal/apperror"
 	"simple-order-go/internal/entity"
+	"simple-order-go/internal/metrics"
 	"simple-order-go/internal/repository"
+	"time"
 )
 
+var (
+	ErrOrderNotFound     = repository.ErrOrderNotFound
+	ErrOrderNotDeleted   = repository.ErrOrderNotDeleted
+	ErrInvalidRetention  = apperror.Validation("invalid_retention", "retention must be positive")
+	ErrInvalidTransition = apperror.Conflict("invalid_transition", "invalid status transition")
+	ErrVersionMismatch   = repository.ErrVersionMismatch
+	ErrConcurrentUpdate  = apperror.Conflict("concurrent_update", "order was modified concurrently, retry the request")
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// OrderService changes orders in transactions that also record each change
+// in the order's audit trail. It also enforces who may do what: customers
+// only reach the orders they own, staff reach every order, and only admins
+// delete, restore and purge orders.
 type OrderService struct {
-	orderRepo repository.IOrderRepository
+	orderRepo  repository.IOrderRepository
+	auditRepo  repository.IAuditRepository
+	transactor repository.ITransactor
 }
 
 type IOrderService interface {
-	CreateOrder(order entity.OrderViewModel) (entity.OrderViewModel, error)
-	GetOrder(orderID int64) (entity.OrderViewModel, error)
-	GetAllOrders() ([]entity.OrderViewModel, error)
-	UpdateOrder(order entity.OrderViewModel) error
-	DeleteOrder(orderID int64) error
+	CreateOrder(ctx context.Context, order entity.OrderViewModel) (entity.OrderViewModel, error)
+	GetOrder(ctx context.Context, orderID int64) (entity.OrderViewModel, error)
+	GetOrdersPage(ctx context.Context, query entity.OrderQuery, page entity.PageRequest) (entity.OrderPage, error)
+	UpdateOrder(ctx context.Context, order entity.OrderViewModel) error
+	DeleteOrder(ctx context.Context, orderID int64, version int64) error
+	RestoreOrder(ctx context.Context, orderID int64) (entity.OrderViewModel, error)
+	PurgeOrders(ctx context.Context, retention time.Duration) (int64, error)
+	TransitionOrder(ctx context.Context, orderID int64, to entity.OrderStatus) (entity.OrderViewModel, error)
+	GetOrderTransitions(ctx context.Context, orderID int64) ([]entity.OrderStatusTransitionViewModel, error)
+	GetOrderHistory(ctx context.Context, orderID int64) ([]entity.AuditEntryViewModel, error)
 }
 
-func NewOrderService(orderRepo repository.IOrderRepository) *OrderService {
-	return &OrderService{orderRepo: orderRepo}
-
+func NewOrderService(
+	orderRepo repository.IOrderRepository,
+	auditRepo repository.IAuditRepository,
+	transactor repository.ITransactor,
+) *OrderService {
+	return &OrderService{orderRepo: orderRepo, auditRepo: auditRepo, transactor: transactor}
 }
 
-func (s *OrderService) CreateOrder(order entity.OrderViewModel) (entity.OrderViewModel, error) {
-	result, err := s.orderRepo.CreateOrder(order.ToEntity())
+func (s *OrderService) CreateOrder(ctx context.Context, order entity.OrderViewModel) (entity.OrderViewModel, error) {
+	arg := order.ToEntity()
+	arg.Owner = callerFrom(ctx).subject
+	arg.Status = entity.OrderStatusPending
+	for i := range arg.Items {
+		arg.Items[i].ID = 0
+	}
+
+	if err := priceOrder(&arg); err != nil {
+		return entity.OrderViewModel{}, err
+	}
+
+	var created entity.OrderViewModel
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		result, err := s.orderRepo.CreateOrder(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		created = result.ToViewModel()
+		return recordAudit(ctx, s.auditRepo, created.ID, entity.AuditActionCreated, nil, &created)
+	})
 	if err != nil {
 		return entity.OrderViewModel{}, err
 	}
 
-	return result.ToViewModel(), nil
+	metrics.OrderCreated(len(created.Items))
+	return created, nil
 }
 
-func (s *OrderService) GetOrder(orderID int64) (entity.OrderViewModel, error) {
-	result, err := s.orderRepo.GetOrder(orderID)
+func (s *OrderService) GetOrder(ctx context.Context, orderID int64) (entity.OrderViewModel, error) {
+	result, err := getAccessibleOrder(ctx, s.orderRepo, orderID)
 	if err != nil {
 		return entity.OrderViewModel{}, err
 	}
@@ -40,28 +95,255 @@ func (s *OrderService) GetOrder(orderID int64) (entity.OrderViewModel, error) {
 	return result.ToViewModel(), nil
 }
 
-func (s *OrderService) GetAllOrders() ([]entity.OrderViewModel, error) {
-	result, err := s.orderRepo.GetAllOrders()
+func (s *OrderService) GetOrdersPage(ctx context.Context, query entity.OrderQuery, page entity.PageRequest) (entity.OrderPage, error) {
+	query, err := normalizeQuery(query)
 	if err != nil {
-		return []entity.OrderViewModel{}, err
+		return entity.OrderPage{}, err
 	}
+	query = scopeQuery(ctx, query)
 
-	return result.ToViewModel(), nil
+	after, err := decodeCursor(query.Sort, page.Cursor)
+	if err != nil {
+		return entity.OrderPage{}, err
+	}
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	// Fetch one extra row to find out whether another page exists.
+	result, err := s.orderRepo.GetOrdersPage(ctx, query, limit+1, after)
+	if err != nil {
+		return entity.OrderPage{}, err
+	}
+
+	var nextCursor string
+	if len(result) > limit {
+		result = result[:limit]
+		nextCursor = encodeCursor(query.Sort, result[limit-1])
+	}
+
+	return entity.OrderPage{Data: result.ToViewModel(), NextCursor: nextCursor}, nil
+}
+
+// UpdateOrder replaces an order. Items carrying an ID update that item of the
+// order, items without one are added, and the order's other items are
+// removed.
+func (s *OrderService) UpdateOrder(ctx context.Context, order entity.OrderViewModel) error {
+	arg := order.ToEntity()
+	if err := checkItemIDs(arg.Items); err != nil {
+		return err
+	}
+
+	if err := priceOrder(&arg); err != nil {
+		return err
+	}
+
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		before, err := s.GetOrder(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.orderRepo.UpdateOrder(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		after, err := s.GetOrder(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, arg.ID, entity.AuditActionUpdated, &before, &after)
+	})
 }
 
-func (s *OrderService) UpdateOrder(order entity.OrderViewModel) error {
-	err := s.orderRepo.UpdateOrder(order.ToEntity())
+func (s *OrderService) DeleteOrder(ctx context.Context, orderID int64, version int64) error {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return err
+	}
+
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		before, err := s.GetOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		err = s.orderRepo.DeleteOrder(ctx, orderID, version)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, orderID, entity.AuditActionDeleted, &before, nil)
+	})
 	if err != nil {
 		return err
 	}
 
+	metrics.OrdersDeleted(metrics.DeleteSoft, 1)
 	return nil
 }
 
-func (s *OrderService) DeleteOrder(orderID int64) error {
-	err := s.orderRepo.DeleteOrder(orderID)
+func (s *OrderService) RestoreOrder(ctx context.Context, orderID int64) (entity.OrderViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return entity.OrderViewModel{}, err
+	}
+
+	var restored entity.OrderViewModel
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		err := s.orderRepo.RestoreOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		restored, err = s.GetOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, orderID, entity.AuditActionRestored, nil, &restored)
+	})
 	if err != nil {
-		return err
+		return entity.OrderViewModel{}, err
+	}
+
+	return restored, nil
+}
+
+// PurgeOrders permanently removes orders that were deleted more than
+// retention ago. Each purged order gets a last audit entry holding what it
+// looked like.
+func (s *OrderService) PurgeOrders(ctx context.Context, retention time.Duration) (int64, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return 0, err
+	}
+
+	if retention <= 0 {
+		return 0, ErrInvalidRetention
+	}
+
+	var purged entity.Orders
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		purged, err = s.orderRepo.PurgeOrders(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+
+		for _, order := range purged {
+			before := order.ToViewModel()
+			if err := recordAudit(ctx, s.auditRepo, order.ID, entity.AuditActionPurged, &before, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	metrics.OrdersDeleted(metrics.DeletePurge, int64(len(purged)))
+	return int64(len(purged)), nil
+}
+
+func (s *OrderService) TransitionOrder(ctx context.Context, orderID int64, to entity.OrderStatus) (entity.OrderViewModel, error) {
+	var after entity.OrderViewModel
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		before, err := s.GetOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		if !before.Status.CanTransitionTo(to) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, before.Status, to)
+		}
+
+		if err := requireTransitionRole(ctx, before.Status, to); err != nil {
+			return err
+		}
+
+		err = s.orderRepo.UpdateOrderStatus(ctx, orderID, before.Status, to)
+		if err != nil {
+			if errors.Is(err, repository.ErrStatusChanged) {
+				return fmt.Errorf("%w: %v", ErrInvalidTransition, err)
+			}
+			return err
+		}
+
+		after = before
+		after.Status = to
+		after.Version++
+		return recordAudit(ctx, s.auditRepo, orderID, entity.AuditActionStatusChanged, &before, &after)
+	})
+	if err != nil {
+		return entity.OrderViewModel{}, err
+	}
+
+	return after, nil
+}
+
+func (s *OrderService) GetOrderTransitions(ctx context.Context, orderID int64) ([]entity.OrderStatusTransitionViewModel, error) {
+	if _, err := s.GetOrder(ctx, orderID); err != nil {
+		return []entity.OrderStatusTransitionViewModel{}, err
+	}
+
+	result, err := s.orderRepo.GetOrderStatusTransitions(ctx, orderID)
+	if err != nil {
+		return []entity.OrderStatusTransitionViewModel{}, err
+	}
+
+	transitions := make([]entity.OrderStatusTransitionViewModel, len(result))
+	for i, transition := range result {
+		transitions[i] = transition.ToViewModel()
+	}
+
+	return transitions, nil
+}
+
+// GetOrderHistory returns the audit trail of an order, oldest entry first.
+// The history of deleted orders stays available to staff and admins.
+func (s *OrderService) GetOrderHistory(ctx context.Context, orderID int64) ([]entity.AuditEntryViewModel, error) {
+	if callerFrom(ctx).ownOrdersOnly() {
+		if _, err := s.GetOrder(ctx, orderID); err != nil {
+			return []entity.AuditEntryViewModel{}, err
+		}
+	}
+
+	result, err := s.auditRepo.GetEntries(ctx, orderID)
+	if err != nil {
+		return []entity.AuditEntryViewModel{}, err
+	}
+
+	if len(result) == 0 {
+		if _, err := s.orderRepo.GetOrder(ctx, orderID); err != nil {
+			return []entity.AuditEntryViewModel{}, err
+		}
+	}
+
+	entries := make([]entity.AuditEntryViewModel, len(result))
+	for i, entry := range result {
+		entries[i] = entry.ToViewModel()
+	}
+
+	return entries, nil
+}
+
+func checkItemIDs(items []entity.Item) error {
+	seen := make(map[int64]bool, len(items))
+	for _, item := range items {
+		if item.ID == 0 {
+			continue
+		}
+
+		if seen[item.ID] {
+			return fmt.Errorf("%w: item %d is listed more than once", ErrInvalidOrder, item.ID)
+		}
+		seen[item.ID] = true
 	}
 
 	return nil
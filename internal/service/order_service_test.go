@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// inlineTransactor runs functions without a transaction.
+type inlineTransactor struct{}
+
+func (inlineTransactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// memoryAudit is an IAuditRepository keeping entries in memory.
+type memoryAudit struct {
+	entries []entity.AuditEntry
+}
+
+func (a *memoryAudit) CreateEntry(ctx context.Context, entry entity.AuditEntry) error {
+	a.entries = append(a.entries, entry)
+	return nil
+}
+
+func (a *memoryAudit) GetEntries(ctx context.Context, orderID int64) ([]entity.AuditEntry, error) {
+	return a.entries, nil
+}
+
+// purgingOrders is the part of IOrderRepository PurgeOrders uses.
+type purgingOrders struct {
+	repository.IOrderRepository
+	purged entity.Orders
+}
+
+func (o *purgingOrders) PurgeOrders(ctx context.Context, deletedBefore time.Time) (entity.Orders, error) {
+	return o.purged, nil
+}
+
+func TestPurgeOrdersRecordsAudit(t *testing.T) {
+	orders := &purgingOrders{purged: entity.Orders{
+		{ID: 1, CustomerName: "Alice", Items: []entity.Item{{ID: 10, Name: "Book", Quantity: 1}}},
+		{ID: 2, CustomerName: "Bob"},
+	}}
+	audit := &memoryAudit{}
+	s := NewOrderService(orders, audit, inlineTransactor{})
+
+	purged, err := s.PurgeOrders(callerContext("admin-1", entity.RoleAdmin), 24*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), purged)
+
+	require.Len(t, audit.entries, 2)
+	for i, entry := range audit.entries {
+		require.Equal(t, orders.purged[i].ID, entry.OrderID)
+		require.Equal(t, entity.AuditActionPurged, entry.Action)
+		require.Equal(t, "admin-1", entry.Actor)
+
+		var changes []entity.AuditChange
+		require.NoError(t, json.Unmarshal(entry.Changes, &changes))
+		require.NotEmpty(t, changes)
+		for _, change := range changes {
+			require.Nil(t, change.To)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "simple-order-go/internal/service"
+
+// TracedOrderService wraps an IOrderService in a span per call.
+type TracedOrderService struct {
+	next IOrderService
+}
+
+func NewTracedOrderService(next IOrderService) *TracedOrderService {
+	return &TracedOrderService{next: next}
+}
+
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Start(ctx, tracerName, "OrderService."+method, trace.WithAttributes(attrs...))
+}
+
+func orderIDAttr(orderID int64) attribute.KeyValue {
+	return attribute.Int64("order.id", orderID)
+}
+
+func (s *TracedOrderService) CreateOrder(ctx context.Context, order entity.OrderViewModel) (result entity.OrderViewModel, err error) {
+	ctx, span := startSpan(ctx, "CreateOrder", attribute.Int("order.items", len(order.Items)))
+	defer func() { tracing.End(span, err) }()
+
+	result, err = s.next.CreateOrder(ctx, order)
+	span.SetAttributes(orderIDAttr(result.ID))
+	return result, err
+}
+
+func (s *TracedOrderService) GetOrder(ctx context.Context, orderID int64) (result entity.OrderViewModel, err error) {
+	ctx, span := startSpan(ctx, "GetOrder", orderIDAttr(orderID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.GetOrder(ctx, orderID)
+}
+
+func (s *TracedOrderService) GetOrdersPage(ctx context.Context, query entity.OrderQuery, page entity.PageRequest) (result entity.OrderPage, err error) {
+	ctx, span := startSpan(ctx, "GetOrdersPage")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.GetOrdersPage(ctx, query, page)
+}
+
+func (s *TracedOrderService) UpdateOrder(ctx context.Context, order entity.OrderViewModel) (err error) {
+	ctx, span := startSpan(ctx, "UpdateOrder", orderIDAttr(order.ID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.UpdateOrder(ctx, order)
+}
+
+func (s *TracedOrderService) DeleteOrder(ctx context.Context, orderID int64, version int64) (err error) {
+	ctx, span := startSpan(ctx, "DeleteOrder", orderIDAttr(orderID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.DeleteOrder(ctx, orderID, version)
+}
+
+func (s *TracedOrderService) RestoreOrder(ctx context.Context, orderID int64) (result entity.OrderViewModel, err error) {
+	ctx, span := startSpan(ctx, "RestoreOrder", orderIDAttr(orderID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.RestoreOrder(ctx, orderID)
+}
+
+func (s *TracedOrderService) PurgeOrders(ctx context.Context, retention time.Duration) (purged int64, err error) {
+	ctx, span := startSpan(ctx, "PurgeOrders")
+	defer func() { tracing.End(span, err) }()
+
+	purged, err = s.next.PurgeOrders(ctx, retention)
+	span.SetAttributes(attribute.Int64("orders.purged", purged))
+	return purged, err
+}
+
+func (s *TracedOrderService) TransitionOrder(ctx context.Context, orderID int64, to entity.OrderStatus) (result entity.OrderViewModel, err error) {
+	ctx, span := startSpan(ctx, "TransitionOrder", orderIDAttr(orderID), attribute.String("order.status", string(to)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.TransitionOrder(ctx, orderID, to)
+}
+
+func (s *TracedOrderService) GetOrderTransitions(ctx context.Context, orderID int64) (result []entity.OrderStatusTransitionViewModel, err error) {
+	ctx, span := startSpan(ctx, "GetOrderTransitions", orderIDAttr(orderID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.GetOrderTransitions(ctx, orderID)
+}
+
+func (s *TracedOrderService) GetOrderHistory(ctx context.Context, orderID int64) (result []entity.AuditEntryViewModel, err error) {
+	ctx, span := startSpan(ctx, "GetOrderHistory", orderIDAttr(orderID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.GetOrderHistory(ctx, orderID)
+}
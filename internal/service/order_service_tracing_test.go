@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"simple-order-go/internal/entity"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanningOrderService starts a span of its own, as the repositories do,
+// to show that calls receive the service span's context.
+type spanningOrderService struct {
+	IOrderService
+}
+
+func (s spanningOrderService) GetOrder(ctx context.Context, orderID int64) (entity.OrderViewModel, error) {
+	_, span := otel.Tracer("test").Start(ctx, "query")
+	defer span.End()
+
+	if orderID != 1 {
+		return entity.OrderViewModel{}, ErrOrderNotFound
+	}
+	return entity.OrderViewModel{ID: orderID}, nil
+}
+
+func TestTracedOrderService(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	traced := NewTracedOrderService(spanningOrderService{})
+
+	_, err := traced.GetOrder(context.Background(), 1)
+	require.NoError(t, err)
+
+	_, err = traced.GetOrder(context.Background(), 2)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+
+	spans := recorder.Ended()
+	require.Equal(t, 4, len(spans))
+
+	query, found, missing := spans[0], spans[1], spans[3]
+	require.Equal(t, "OrderService.GetOrder", found.Name())
+	require.Equal(t, found.SpanContext().SpanID(), query.Parent().SpanID())
+	require.Contains(t, found.Attributes(), attribute.Int64("order.id", 1))
+	require.Empty(t, found.Events())
+
+	require.Contains(t, missing.Attributes(), attribute.Int64("order.id", 2))
+	require.Equal(t, 1, len(missing.Events()))
+}
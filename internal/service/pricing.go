@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrInvalidOrder = apperror.Validation("invalid_order", "invalid order")
+
+// Amounts are stored as numeric(19,4): at most 4 decimal places and less than
+// 10^15.
+const maxPriceScale = 4
+
+var maxAmount = decimal.New(1, 15)
+
+// priceOrder fills in the line totals, subtotal and grand total of an order
+// from its items' unit prices. All items must share one currency. Prices with
+// more decimal places, and amounts larger, than can be stored are rejected
+// rather than rounded by the database.
+func priceOrder(order *entity.Order) error {
+	order.Currency = ""
+	order.Subtotal = decimal.Zero
+
+	for i := range order.Items {
+		item := &order.Items[i]
+
+		if item.UnitPrice.IsNegative() {
+			return fmt.Errorf("%w: item %q has a negative unit price", ErrInvalidOrder, item.Name)
+		}
+
+		if !item.UnitPrice.Equal(item.UnitPrice.Truncate(maxPriceScale)) {
+			return fmt.Errorf("%w: item %q has a unit price with more than %d decimal places", ErrInvalidOrder, item.Name, maxPriceScale)
+		}
+
+		if order.Currency == "" {
+			order.Currency = item.Currency
+		} else if item.Currency != order.Currency {
+			return fmt.Errorf("%w: items are priced in both %s and %s", ErrInvalidOrder, order.Currency, item.Currency)
+		}
+
+		item.LineTotal = item.UnitPrice.Mul(decimal.NewFromInt32(item.Quantity))
+		order.Subtotal = order.Subtotal.Add(item.LineTotal)
+		if item.LineTotal.GreaterThanOrEqual(maxAmount) || order.Subtotal.GreaterThanOrEqual(maxAmount) {
+			return fmt.Errorf("%w: the order total must be less than %s", ErrInvalidOrder, maxAmount)
+		}
+	}
+
+	// Taxes, shipping and discounts aren't modelled yet, so the grand total
+	// is the subtotal.
+	order.Total = order.Subtotal
+
+	return nil
+}
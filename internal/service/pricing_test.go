@@ -0,0 +1,147 @@
+package service
+
+import (
+	"simple-order-go/internal/entity"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func pricedItem(name string, quantity int32, unitPrice string, currency string) entity.Item {
+	return entity.Item{
+		Name:      name,
+		Quantity:  quantity,
+		UnitPrice: decimal.RequireFromString(unitPrice),
+		Currency:  currency,
+	}
+}
+
+func TestPriceOrder(t *testing.T) {
+	testCases := []struct {
+		name       string
+		items      []entity.Item
+		err        error
+		currency   string
+		lineTotals []string
+		subtotal   string
+	}{
+		{
+			name: "OK",
+			items: []entity.Item{
+				pricedItem("Book", 2, "12.50", "EUR"),
+				pricedItem("Pen", 3, "0.9999", "EUR"),
+			},
+			currency:   "EUR",
+			lineTotals: []string{"25", "2.9997"},
+			subtotal:   "27.9997",
+		},
+		{
+			name:       "FreeItem",
+			items:      []entity.Item{pricedItem("Sample", 5, "0", "USD")},
+			currency:   "USD",
+			lineTotals: []string{"0"},
+			subtotal:   "0",
+		},
+		{
+			name:       "ZeroQuantity",
+			items:      []entity.Item{pricedItem("Book", 0, "12.50", "EUR")},
+			currency:   "EUR",
+			lineTotals: []string{"0"},
+			subtotal:   "0",
+		},
+		{
+			name:       "NoItems",
+			lineTotals: []string{},
+			subtotal:   "0",
+		},
+		{
+			name:  "NegativeUnitPrice",
+			items: []entity.Item{pricedItem("Refund", 1, "-1", "EUR")},
+			err:   ErrInvalidOrder,
+		},
+		{
+			name: "NegativeUnitPriceAfterValidItem",
+			items: []entity.Item{
+				pricedItem("Book", 1, "10", "EUR"),
+				pricedItem("Refund", 1, "-0.01", "EUR"),
+			},
+			err: ErrInvalidOrder,
+		},
+		{
+			name:       "TrailingZeros",
+			items:      []entity.Item{pricedItem("Book", 2, "1.50000", "EUR")},
+			currency:   "EUR",
+			lineTotals: []string{"3"},
+			subtotal:   "3",
+		},
+		{
+			name:       "LargestTotal",
+			items:      []entity.Item{pricedItem("Car", 1, "999999999999999.9999", "EUR")},
+			currency:   "EUR",
+			lineTotals: []string{"999999999999999.9999"},
+			subtotal:   "999999999999999.9999",
+		},
+		{
+			name:  "TooManyDecimalPlaces",
+			items: []entity.Item{pricedItem("Book", 1, "0.00001", "EUR")},
+			err:   ErrInvalidOrder,
+		},
+		{
+			name:  "UnitPriceTooLarge",
+			items: []entity.Item{pricedItem("Car", 1, "1000000000000000", "EUR")},
+			err:   ErrInvalidOrder,
+		},
+		{
+			name:  "LineTotalTooLarge",
+			items: []entity.Item{pricedItem("Car", 2, "500000000000000", "EUR")},
+			err:   ErrInvalidOrder,
+		},
+		{
+			name: "SubtotalTooLarge",
+			items: []entity.Item{
+				pricedItem("Car", 1, "600000000000000", "EUR"),
+				pricedItem("Boat", 1, "400000000000000", "EUR"),
+			},
+			err: ErrInvalidOrder,
+		},
+		{
+			name: "MixedCurrencies",
+			items: []entity.Item{
+				pricedItem("Book", 1, "10", "EUR"),
+				pricedItem("Pen", 1, "1", "USD"),
+			},
+			err: ErrInvalidOrder,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Stale totals must not leak into the new ones.
+			order := entity.Order{
+				Items:    tc.items,
+				Currency: "GBP",
+				Subtotal: decimal.NewFromInt(99),
+				Total:    decimal.NewFromInt(99),
+			}
+
+			err := priceOrder(&order)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, tc.currency, order.Currency)
+			lineTotals := []string{}
+			for _, item := range order.Items {
+				lineTotals = append(lineTotals, item.LineTotal.String())
+			}
+			require.Equal(t, tc.lineTotals, lineTotals)
+			require.Equal(t, tc.subtotal, order.Subtotal.String())
+			require.True(t, order.Total.Equal(order.Subtotal))
+		})
+	}
+}
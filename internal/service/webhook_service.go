@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/internal/webhook"
+	"simple-order-go/pkg/config"
+)
+
+var (
+	ErrSubscriptionNotFound = repository.ErrSubscriptionNotFound
+	ErrDeliveryNotFound     = repository.ErrDeliveryNotFound
+)
+
+// secretBytes is the size of generated subscription secrets.
+const secretBytes = 32
+
+// WebhookService manages webhook subscriptions. Subscriptions receive the
+// events of every order, so only admins may manage them. Their endpoints must
+// resolve to public addresses, unless private ones are allowed.
+type WebhookService struct {
+	webhookRepo  repository.IWebhookRepository
+	allowPrivate bool
+}
+
+type IWebhookService interface {
+	CreateSubscription(ctx context.Context, subscription entity.WebhookSubscriptionViewModel) (entity.WebhookSubscriptionViewModel, error)
+	GetSubscriptions(ctx context.Context) ([]entity.WebhookSubscriptionViewModel, error)
+	GetSubscription(ctx context.Context, subscriptionID int64) (entity.WebhookSubscriptionViewModel, error)
+	DeleteSubscription(ctx context.Context, subscriptionID int64) error
+	GetDeliveries(ctx context.Context, subscriptionID int64) ([]entity.WebhookDeliveryViewModel, error)
+	GetDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (entity.WebhookDeliveryViewModel, error)
+	RedeliverDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (entity.WebhookDeliveryViewModel, error)
+}
+
+func NewWebhookService(webhookRepo repository.IWebhookRepository, cfg config.Webhooks) *WebhookService {
+	return &WebhookService{webhookRepo: webhookRepo, allowPrivate: cfg.AllowPrivateAddresses}
+}
+
+// CreateSubscription stores a subscription, generating its secret if none
+// was given. The result is the only place the secret is returned.
+func (s *WebhookService) CreateSubscription(ctx context.Context, subscription entity.WebhookSubscriptionViewModel) (entity.WebhookSubscriptionViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return entity.WebhookSubscriptionViewModel{}, err
+	}
+
+	if !s.allowPrivate {
+		if err := checkEndpoint(ctx, subscription.URL); err != nil {
+			return entity.WebhookSubscriptionViewModel{}, err
+		}
+	}
+
+	arg := subscription.ToEntity()
+	arg.ID = 0
+	if arg.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return entity.WebhookSubscriptionViewModel{}, err
+		}
+		arg.Secret = secret
+	}
+
+	created, err := s.webhookRepo.CreateSubscription(ctx, arg)
+	if err != nil {
+		return entity.WebhookSubscriptionViewModel{}, err
+	}
+
+	result := created.ToViewModel()
+	result.Secret = created.Secret
+	return result, nil
+}
+
+func (s *WebhookService) GetSubscriptions(ctx context.Context) ([]entity.WebhookSubscriptionViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return []entity.WebhookSubscriptionViewModel{}, err
+	}
+
+	result, err := s.webhookRepo.GetSubscriptions(ctx)
+	if err != nil {
+		return []entity.WebhookSubscriptionViewModel{}, err
+	}
+
+	subscriptions := make([]entity.WebhookSubscriptionViewModel, len(result))
+	for i, subscription := range result {
+		subscriptions[i] = subscription.ToViewModel()
+	}
+
+	return subscriptions, nil
+}
+
+func (s *WebhookService) GetSubscription(ctx context.Context, subscriptionID int64) (entity.WebhookSubscriptionViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return entity.WebhookSubscriptionViewModel{}, err
+	}
+
+	result, err := s.webhookRepo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return entity.WebhookSubscriptionViewModel{}, err
+	}
+
+	return result.ToViewModel(), nil
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, subscriptionID int64) error {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return err
+	}
+
+	return s.webhookRepo.DeleteSubscription(ctx, subscriptionID)
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, subscriptionID int64) ([]entity.WebhookDeliveryViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return []entity.WebhookDeliveryViewModel{}, err
+	}
+
+	result, err := s.webhookRepo.GetDeliveries(ctx, subscriptionID)
+	if err != nil {
+		return []entity.WebhookDeliveryViewModel{}, err
+	}
+
+	deliveries := make([]entity.WebhookDeliveryViewModel, len(result))
+	for i, delivery := range result {
+		deliveries[i] = delivery.ToViewModel()
+	}
+
+	return deliveries, nil
+}
+
+func (s *WebhookService) GetDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (entity.WebhookDeliveryViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return entity.WebhookDeliveryViewModel{}, err
+	}
+
+	result, err := s.webhookRepo.GetDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return entity.WebhookDeliveryViewModel{}, err
+	}
+
+	return result.ToViewModel(), nil
+}
+
+// RedeliverDelivery queues a delivery to be sent again, including
+// dead-lettered and already successful ones.
+func (s *WebhookService) RedeliverDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (entity.WebhookDeliveryViewModel, error) {
+	if err := requireRole(ctx, entity.RoleAdmin); err != nil {
+		return entity.WebhookDeliveryViewModel{}, err
+	}
+
+	result, err := s.webhookRepo.RedeliverDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return entity.WebhookDeliveryViewModel{}, err
+	}
+
+	return result.ToViewModel(), nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// checkEndpoint reports an endpoint that doesn't resolve, or resolves to an
+// address that isn't public, as a validation error.
+func checkEndpoint(ctx context.Context, url string) error {
+	err := webhook.CheckURL(ctx, url)
+	if err == nil {
+		return nil
+	}
+
+	field := apperror.FieldError{Field: "url", Code: "resolvable", Message: "host could not be resolved"}
+	if errors.Is(err, webhook.ErrNonPublicAddress) {
+		field = apperror.FieldError{Field: "url", Code: "public_address", Message: "must resolve to public addresses only"}
+	}
+	return apperror.Validation("validation_failed", "request validation failed", field)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/pkg/config"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createOnlyWebhooks is the part of IWebhookRepository CreateSubscription
+// uses.
+type createOnlyWebhooks struct {
+	repository.IWebhookRepository
+	created []entity.WebhookSubscription
+}
+
+func (w *createOnlyWebhooks) CreateSubscription(ctx context.Context, subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	subscription.ID = int64(len(w.created) + 1)
+	w.created = append(w.created, subscription)
+	return subscription, nil
+}
+
+func TestCreateSubscriptionEndpoint(t *testing.T) {
+	admin := callerContext("admin-1", entity.RoleAdmin)
+
+	testCases := []struct {
+		name         string
+		url          string
+		allowPrivate bool
+		code         string
+	}{
+		{name: "Public", url: "https://93.184.216.34/hooks"},
+		{name: "Loopback", url: "http://127.0.0.1:8080/hooks", code: "public_address"},
+		{name: "Metadata", url: "http://169.254.169.254/latest/meta-data", code: "public_address"},
+		{name: "Private", url: "http://10.0.0.5/hooks", code: "public_address"},
+		{name: "Localhost", url: "http://localhost/hooks", code: "public_address"},
+		{name: "PrivateAllowed", url: "http://127.0.0.1:8080/hooks", allowPrivate: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			webhooks := &createOnlyWebhooks{}
+			s := NewWebhookService(webhooks, config.Webhooks{AllowPrivateAddresses: tc.allowPrivate})
+
+			_, err := s.CreateSubscription(admin, entity.WebhookSubscriptionViewModel{
+				URL:        tc.url,
+				EventTypes: []entity.EventType{entity.EventOrderCreated},
+			})
+			if tc.code == "" {
+				require.NoError(t, err)
+				require.Len(t, webhooks.created, 1)
+				return
+			}
+
+			appErr := apperror.As(err)
+			require.Equal(t, apperror.KindValidation, appErr.Kind)
+			require.Equal(t, tc.code, appErr.Fields[0].Code)
+			require.Empty(t, webhooks.created)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"simple-order-go/internal/apperror"
+	"simple-order-go/pkg/config"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	testCases := []struct {
+		name   string
+		err    error
+		status codes.Code
+		events int
+	}{
+		{name: "OK", status: codes.Unset},
+		{name: "NotFound", err: apperror.NotFound("order_not_found", "order not found"), status: codes.Unset, events: 1},
+		{name: "Internal", err: errors.New("connection refused"), status: codes.Error, events: 1},
+	}
+
+	for _, tc := range testCases {
+		_, span := Start(context.Background(), "test", tc.name)
+		End(span, tc.err)
+	}
+
+	spans := recorder.Ended()
+	require.Equal(t, len(testCases), len(spans))
+	for i, tc := range testCases {
+		require.Equal(t, tc.name, spans[i].Name())
+		require.Equal(t, tc.status, spans[i].Status().Code)
+		require.Equal(t, tc.events, len(spans[i].Events()))
+	}
+}
+
+func TestInit(t *testing.T) {
+	shutdown, err := Init(context.Background(), config.Tracing{Exporter: ExporterNone})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	shutdown, err = Init(context.Background(), config.Tracing{Exporter: ExporterStdout, ServiceName: "test", SampleRatio: 1})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, err = Init(context.Background(), config.Tracing{Exporter: "zipkin"})
+	require.Error(t, err)
+}
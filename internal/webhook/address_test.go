@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublic(t *testing.T) {
+	testCases := []struct {
+		addr   string
+		public bool
+	}{
+		{addr: "93.184.216.34", public: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", public: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "100.64.0.1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "fd00::1"},
+		{addr: "fe80::1"},
+		{addr: "224.0.0.1"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "64:ff9b::a9fe:a9fe"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.addr, func(t *testing.T) {
+			require.Equal(t, tc.public, IsPublic(netip.MustParseAddr(tc.addr)))
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	require.NoError(t, CheckURL(context.Background(), "https://93.184.216.34/hooks"))
+	require.ErrorIs(t, CheckURL(context.Background(), "http://127.0.0.1:8080/hooks"), ErrNonPublicAddress)
+	require.ErrorIs(t, CheckURL(context.Background(), "http://169.254.169.254/latest/meta-data"), ErrNonPublicAddress)
+	require.ErrorIs(t, CheckURL(context.Background(), "http://[::1]/hooks"), ErrNonPublicAddress)
+	require.ErrorIs(t, CheckURL(context.Background(), "http://localhost/hooks"), ErrNonPublicAddress)
+}
@@ -0,0 +1,212 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/pkg/config"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 50
+	defaultMaxAttempts  = 8
+	defaultClaimLease   = 5 * time.Minute
+
+	// leaseMargin is kept free at the end of a lease for recording the last
+	// attempt.
+	leaseMargin = 10 * time.Second
+
+	minRetryDelay = 10 * time.Second
+	maxRetryDelay = 6 * time.Hour
+
+	// maxErrorBody is how much of a failed response is kept in the log.
+	maxErrorBody = 512
+)
+
+// Publisher hands events from the outbox to the subscriptions that want them
+// by queueing a delivery for each.
+type Publisher struct {
+	webhookRepo repository.IWebhookRepository
+}
+
+func NewPublisher(webhookRepo repository.IWebhookRepository) *Publisher {
+	return &Publisher{webhookRepo: webhookRepo}
+}
+
+func (p *Publisher) Publish(ctx context.Context, event entity.Event) error {
+	_, err := p.webhookRepo.EnqueueDeliveries(ctx, event)
+	return err
+}
+
+// Deliverer sends queued deliveries to their subscriptions. A delivery
+// succeeds on any 2xx response; redirects aren't followed and count as
+// failures. Endpoints on non-public addresses are refused unless
+// AllowPrivateAddresses is set. Failed deliveries are retried with
+// exponential backoff until they have failed MaxAttempts times, after which
+// they are dead-lettered.
+type Deliverer struct {
+	webhookRepo  repository.IWebhookRepository
+	client       *http.Client
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	timeout      time.Duration
+	lease        time.Duration
+}
+
+func NewDeliverer(webhookRepo repository.IWebhookRepository, cfg config.Webhooks) *Deliverer {
+	d := &Deliverer{
+		webhookRepo:  webhookRepo,
+		pollInterval: cfg.PollInterval,
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+	}
+	if d.pollInterval <= 0 {
+		d.pollInterval = defaultPollInterval
+	}
+	if d.batchSize <= 0 {
+		d.batchSize = defaultBatchSize
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = defaultMaxAttempts
+	}
+
+	d.timeout = cfg.Timeout
+	if d.timeout <= 0 {
+		d.timeout = defaultTimeout
+	}
+	d.client = newClient(d.timeout, cfg.AllowPrivateAddresses)
+
+	maxLease := cfg.ClaimLease
+	if maxLease <= 0 {
+		maxLease = defaultClaimLease
+	}
+	d.lease = claimLease(d.batchSize, d.timeout, maxLease)
+	return d
+}
+
+// claimLease is how long a claimed batch is reserved, like the outbox
+// dispatcher's, capped at maxLease.
+func claimLease(batchSize int, timeout time.Duration, maxLease time.Duration) time.Duration {
+	lease := time.Duration(batchSize)*timeout + leaseMargin
+	if lease > maxLease {
+		lease = maxLease
+	}
+	if lease < timeout+leaseMargin {
+		lease = timeout + leaseMargin
+	}
+	return lease
+}
+
+// Run delivers until ctx is cancelled, polling like the outbox dispatcher.
+func (d *Deliverer) Run(ctx context.Context) {
+	for {
+		n, err := d.Deliver(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "webhook delivery failed", "error", err)
+		}
+
+		if n == d.batchSize && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.pollInterval):
+		}
+	}
+}
+
+// Deliver sends one batch of due deliveries and returns how many it claimed.
+func (d *Deliverer) Deliver(ctx context.Context) (int, error) {
+	deliveries, err := d.webhookRepo.ClaimDeliveries(ctx, d.batchSize, d.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	// Deliveries left once the lease can't cover another request are sent
+	// after they are claimed again, not twice.
+	deadline := time.Now().Add(d.lease - d.timeout - leaseMargin)
+	for i, delivery := range deliveries {
+		if i > 0 && time.Now().After(deadline) {
+			break
+		}
+
+		if err := d.deliver(ctx, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (d *Deliverer) deliver(ctx context.Context, delivery entity.WebhookDelivery) error {
+	start := time.Now()
+	status, err := d.send(ctx, delivery)
+	attempt := entity.WebhookDeliveryAttempt{
+		DeliveryID:     delivery.ID,
+		ResponseStatus: status,
+		DurationMs:     time.Since(start).Milliseconds(),
+	}
+
+	if err == nil {
+		return d.webhookRepo.RecordAttempt(ctx, attempt, entity.DeliverySucceeded, time.Now())
+	}
+
+	attempt.Error = err.Error()
+	if delivery.Attempts >= d.maxAttempts {
+		return d.webhookRepo.RecordAttempt(ctx, attempt, entity.DeliveryDead, time.Now())
+	}
+
+	return d.webhookRepo.RecordAttempt(ctx, attempt, entity.DeliveryPending, time.Now().Add(retryDelay(delivery.Attempts)))
+}
+
+// send POSTs a delivery and returns the response status, if any.
+func (d *Deliverer) send(ctx context.Context, delivery entity.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.EventType))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// retryDelay is the backoff after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
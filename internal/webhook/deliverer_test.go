@@ -0,0 +1,282 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-go/internal/entity"
+	"simple-order-go/internal/repository"
+	"simple-order-go/pkg/config"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// memoryWebhooks is the part of IWebhookRepository the deliverer uses, kept
+// in memory.
+type memoryWebhooks struct {
+	repository.IWebhookRepository
+
+	mu         sync.Mutex
+	deliveries []entity.WebhookDelivery
+}
+
+func (w *memoryWebhooks) add(url string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := int64(len(w.deliveries) + 1)
+	event := entity.Event{ID: id, Type: entity.EventOrderCreated, OrderID: 1, Data: json.RawMessage(`{"id":1}`)}
+	payload, _ := json.Marshal(event)
+
+	w.deliveries = append(w.deliveries, entity.WebhookDelivery{
+		ID:             id,
+		SubscriptionID: 1,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+		Status:         entity.DeliveryPending,
+		Subscription:   entity.WebhookSubscription{ID: 1, URL: url, Secret: testSecret},
+	})
+}
+
+func (w *memoryWebhooks) get(id int64) entity.WebhookDelivery {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.deliveries[id-1]
+}
+
+// due makes a delivery due right away, as if its backoff had passed.
+func (w *memoryWebhooks) due(id int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.deliveries[id-1].NextAttemptAt = time.Now()
+}
+
+func (w *memoryWebhooks) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var claimed []entity.WebhookDelivery
+	for i := range w.deliveries {
+		delivery := &w.deliveries[i]
+		if delivery.Status != entity.DeliveryPending || delivery.NextAttemptAt.After(time.Now()) || len(claimed) == limit {
+			continue
+		}
+
+		delivery.Attempts++
+		delivery.NextAttemptAt = time.Now().Add(lease)
+		claimed = append(claimed, *delivery)
+	}
+	return claimed, nil
+}
+
+func (w *memoryWebhooks) RecordAttempt(ctx context.Context, attempt entity.WebhookDeliveryAttempt, status entity.DeliveryStatus, nextAttemptAt time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delivery := &w.deliveries[attempt.DeliveryID-1]
+	delivery.Log = append(delivery.Log, attempt)
+	delivery.Status = status
+	delivery.LastError = attempt.Error
+	delivery.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func TestDeliverSignsRequests(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhooks := &memoryWebhooks{}
+	webhooks.add(server.URL)
+
+	n, err := NewDeliverer(webhooks, config.Webhooks{AllowPrivateAddresses: true}).Deliver(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	delivery := webhooks.get(1)
+	require.Equal(t, entity.DeliverySucceeded, delivery.Status)
+	require.Equal(t, 1, len(delivery.Log))
+	require.Equal(t, http.StatusNoContent, delivery.Log[0].ResponseStatus)
+
+	require.Equal(t, http.MethodPost, got.Method)
+	require.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	require.Equal(t, string(entity.EventOrderCreated), got.Header.Get(EventHeader))
+	require.Equal(t, "1", got.Header.Get(DeliveryHeader))
+	require.JSONEq(t, string(delivery.Payload), string(body))
+
+	timestamp, err := strconv.ParseInt(got.Header.Get(TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	require.True(t, Verify(testSecret, timestamp, body, got.Header.Get(SignatureHeader)))
+	require.False(t, Verify("another secret", timestamp, body, got.Header.Get(SignatureHeader)))
+}
+
+func TestDeliverRetriesAndDeadLetters(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		http.Error(w, "try later", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	webhooks := &memoryWebhooks{}
+	webhooks.add(server.URL)
+	deliverer := NewDeliverer(webhooks, config.Webhooks{MaxAttempts: 3, AllowPrivateAddresses: true})
+
+	_, err := deliverer.Deliver(context.Background())
+	require.NoError(t, err)
+
+	delivery := webhooks.get(1)
+	require.Equal(t, entity.DeliveryPending, delivery.Status)
+	require.Equal(t, http.StatusServiceUnavailable, delivery.Log[0].ResponseStatus)
+	require.Contains(t, delivery.LastError, "try later")
+	require.WithinDuration(t, time.Now().Add(minRetryDelay), delivery.NextAttemptAt, time.Second)
+
+	// Not due until the backoff passed.
+	n, err := deliverer.Deliver(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	for i := 0; i < 2; i++ {
+		webhooks.due(1)
+		_, err = deliverer.Deliver(context.Background())
+		require.NoError(t, err)
+	}
+
+	delivery = webhooks.get(1)
+	require.Equal(t, entity.DeliveryDead, delivery.Status)
+	require.Equal(t, 3, len(delivery.Log))
+	require.Equal(t, 3, calls)
+
+	// Dead deliveries aren't tried again.
+	webhooks.due(1)
+	n, err = deliverer.Deliver(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+}
+
+func TestDeliverUnreachableEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	webhooks := &memoryWebhooks{}
+	webhooks.add(server.URL)
+
+	_, err := NewDeliverer(webhooks, config.Webhooks{AllowPrivateAddresses: true}).Deliver(context.Background())
+	require.NoError(t, err)
+
+	delivery := webhooks.get(1)
+	require.Equal(t, entity.DeliveryPending, delivery.Status)
+	require.Zero(t, delivery.Log[0].ResponseStatus)
+	require.NotEmpty(t, delivery.LastError)
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/elsewhere" {
+			redirected = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Redirect(w, r, "/elsewhere", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	webhooks := &memoryWebhooks{}
+	webhooks.add(server.URL)
+
+	_, err := NewDeliverer(webhooks, config.Webhooks{AllowPrivateAddresses: true}).Deliver(context.Background())
+	require.NoError(t, err)
+
+	delivery := webhooks.get(1)
+	require.False(t, redirected)
+	require.Equal(t, entity.DeliveryPending, delivery.Status)
+	require.Equal(t, http.StatusTemporaryRedirect, delivery.Log[0].ResponseStatus)
+}
+
+func TestDeliverRefusesPrivateAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhooks := &memoryWebhooks{}
+	webhooks.add(server.URL)
+
+	_, err := NewDeliverer(webhooks, config.Webhooks{}).Deliver(context.Background())
+	require.NoError(t, err)
+
+	delivery := webhooks.get(1)
+	require.False(t, called)
+	require.Equal(t, entity.DeliveryPending, delivery.Status)
+	require.Zero(t, delivery.Log[0].ResponseStatus)
+	require.Contains(t, delivery.LastError, ErrNonPublicAddress.Error())
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, minRetryDelay, retryDelay(1))
+	require.Equal(t, 2*minRetryDelay, retryDelay(2))
+	require.Equal(t, 8*minRetryDelay, retryDelay(4))
+	require.Equal(t, maxRetryDelay, retryDelay(50))
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1}`)
+
+	signature := Sign(testSecret, 1700000000, body)
+	require.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	require.True(t, Verify(testSecret, 1700000000, body, signature))
+	require.False(t, Verify(testSecret, 1700000001, body, signature))
+	require.False(t, Verify(testSecret, 1700000000, []byte(`{"id":2}`), signature))
+}
+
+func TestClaimLease(t *testing.T) {
+	// Every request in a batch of 10 may take the whole 10s timeout.
+	require.Equal(t, 10*10*time.Second+leaseMargin, claimLease(10, 10*time.Second, time.Hour))
+	require.Equal(t, 5*time.Minute, claimLease(50, 10*time.Second, 5*time.Minute))
+	require.Equal(t, 10*time.Second+leaseMargin, claimLease(50, 10*time.Second, time.Second))
+}
+
+func TestDeliverStopsWhenLeaseRunsOut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhooks := &memoryWebhooks{}
+	webhooks.add(server.URL)
+	webhooks.add(server.URL)
+
+	deliverer := NewDeliverer(webhooks, config.Webhooks{AllowPrivateAddresses: true})
+	// A lease too short for more than one request leaves the rest of the
+	// batch.
+	deliverer.lease = deliverer.timeout
+
+	n, err := deliverer.Deliver(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, entity.DeliverySucceeded, webhooks.get(1).Status)
+	require.Equal(t, entity.DeliveryPending, webhooks.get(2).Status)
+	require.Empty(t, webhooks.get(2).Log)
+}
@@ -1,35 +1,174 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
 	"simple-order-go/api"
+	"simple-order-go/internal/auth"
 	"simple-order-go/internal/handler"
+	"simple-order-go/internal/health"
+	"simple-order-go/internal/idempotency"
+	"simple-order-go/internal/logging"
+	"simple-order-go/internal/outbox"
+	"simple-order-go/internal/ratelimit"
 	"simple-order-go/internal/repository"
 	"simple-order-go/internal/service"
+	"simple-order-go/internal/tracing"
+	"simple-order-go/internal/webhook"
 	config "simple-order-go/pkg/config"
 	database "simple-order-go/pkg/db"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	cfg := config.LoadConfig("app.yaml")
 
+	logger, err := logging.New(os.Stdout, cfg.Log)
+	if err != nil {
+		fatal("Init logging error", err)
+	}
+	slog.SetDefault(logger)
+	if cfg.Log.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("Init tracing error", err)
+	}
+
 	db, err := database.InitDB(cfg.Database)
 	if err != nil {
-		log.Fatalf("Init DB error: %v", err)
+		fatal("Init DB error", err)
 	}
 
+	transactor := repository.NewTransactor(db)
+	auditRepo := repository.NewAuditRepository(db)
+
 	orderRepo := repository.NewOrderRepository(db)
-	orderService := service.NewOrderService(orderRepo)
-	handler := handler.NewOrderHandler(orderService)
+	orderService := service.NewOrderService(orderRepo, auditRepo, transactor)
+	orderHandler := handler.NewOrderHandler(service.NewTracedOrderService(orderService))
+
+	itemRepo := repository.NewItemRepository(db)
+	itemService := service.NewItemService(orderRepo, itemRepo, auditRepo, transactor)
+	itemHandler := handler.NewItemHandler(itemService)
+
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg.Idempotency)
+	idempotencyHandler := handler.NewIdempotencyHandler(idempotencyService)
+
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookService := service.NewWebhookService(webhookRepo, cfg.Webhooks)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+
+	publisher, err := outbox.NewPublisher(cfg.Outbox)
+	if err != nil {
+		fatal("Init outbox publisher error", err)
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	runWorker := func(run func(context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+
+	outboxRepo := repository.NewOutboxRepository(db)
+	dispatcher := outbox.NewDispatcher(outboxRepo, outbox.NewMultiPublisher(publisher, webhook.NewPublisher(webhookRepo)), cfg.Outbox)
+	runWorker(dispatcher.Run)
+
+	deliverer := webhook.NewDeliverer(webhookRepo, cfg.Webhooks)
+	runWorker(deliverer.Run)
 
-	server := api.NewServer(*handler)
+	cleaner := idempotency.NewCleaner(idempotencyRepo, cfg.Idempotency)
+	runWorker(cleaner.Run)
+
+	var authenticator auth.Authenticator
+	if cfg.Auth.Enabled {
+		verifier, err := auth.NewJWTVerifier(cfg.Auth)
+		if err != nil {
+			fatal("Init authentication error", err)
+		}
+		authenticator = auth.NewChain(auth.NewAPIKeyAuthenticator(apiKeyRepo), verifier)
+	}
+
+	var limiter ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		limiter = ratelimit.NewMemoryLimiter()
+	}
+
+	migrationVersion, err := database.LatestMigration(cfg.Database.MigrationsPath)
 	if err != nil {
-		log.Fatal("cannot create server: ", err)
+		fatal("Read migrations error", err)
 	}
 
-	err = server.Start(fmt.Sprintf("%s:%d", cfg.App.Host, cfg.App.Port))
+	checker := health.NewChecker(health.Database(db), health.Migrations(db, migrationVersion))
+	healthHandler := handler.NewHealthHandler(checker)
+
+	server, err := api.NewServer(cfg, authenticator, limiter, *orderHandler, *itemHandler, *idempotencyHandler, *webhookHandler, *apiKeyHandler, *healthHandler)
 	if err != nil {
-		log.Fatal("cannot start server: ", err)
+		fatal("Init server error", err)
 	}
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			fatal("Start server error", err)
+		}
+	case <-signals.Done():
+		slog.Info("shutting down")
+	}
+
+	// Fail readiness first and keep serving for a while, so the load
+	// balancer stops routing requests here before the listener closes.
+	checker.ShuttingDown()
+	time.Sleep(cfg.App.ShutdownDelay)
+
+	// Stop in the reverse order of the dependencies: first the requests,
+	// then the workers, both of which need the database, then the database.
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+	defer cancelShutdown()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("HTTP server shutdown error", "error", err)
+	}
+
+	stopWorkers()
+	workers.Wait()
+
+	if err := database.Close(db); err != nil {
+		slog.Error("Close DB error", "error", err)
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Flush traces error", "error", err)
+	}
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1 @@
+CREATE INDEX ON "orders" ("created_at", "id");
@@ -0,0 +1,13 @@
+ALTER TABLE "orders" ADD COLUMN "status" varchar NOT NULL DEFAULT 'pending';
+
+CREATE TABLE "order_status_transitions" (
+  "id" bigserial PRIMARY KEY,
+  "order_id" bigint NOT NULL,
+  "from_status" varchar NOT NULL,
+  "to_status" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "order_status_transitions" ADD FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "order_status_transitions" ("order_id");
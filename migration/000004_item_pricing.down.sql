@@ -0,0 +1,9 @@
+ALTER TABLE "orders"
+  DROP COLUMN IF EXISTS "total",
+  DROP COLUMN IF EXISTS "subtotal",
+  DROP COLUMN IF EXISTS "currency";
+
+ALTER TABLE "items"
+  DROP COLUMN IF EXISTS "line_total",
+  DROP COLUMN IF EXISTS "currency",
+  DROP COLUMN IF EXISTS "unit_price";
@@ -0,0 +1,9 @@
+ALTER TABLE "items"
+  ADD COLUMN "unit_price" numeric(19,4) NOT NULL DEFAULT 0,
+  ADD COLUMN "currency" varchar(3) NOT NULL DEFAULT '',
+  ADD COLUMN "line_total" numeric(19,4) NOT NULL DEFAULT 0;
+
+ALTER TABLE "orders"
+  ADD COLUMN "currency" varchar(3) NOT NULL DEFAULT '',
+  ADD COLUMN "subtotal" numeric(19,4) NOT NULL DEFAULT 0,
+  ADD COLUMN "total" numeric(19,4) NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+CREATE TABLE "idempotency_keys" (
+  "key" varchar PRIMARY KEY,
+  "fingerprint" varchar NOT NULL,
+  "status_code" int NOT NULL DEFAULT 0,
+  "response_body" bytea,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz
+);
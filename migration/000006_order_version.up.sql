@@ -0,0 +1 @@
+ALTER TABLE "orders" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
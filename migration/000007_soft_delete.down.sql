@@ -0,0 +1,5 @@
+DELETE FROM "items" WHERE "deleted_at" IS NOT NULL;
+DELETE FROM "orders" WHERE "deleted_at" IS NOT NULL;
+
+ALTER TABLE "items" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "deleted_at";
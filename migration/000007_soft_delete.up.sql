@@ -0,0 +1,5 @@
+ALTER TABLE "orders" ADD COLUMN "deleted_at" timestamptz;
+ALTER TABLE "items" ADD COLUMN "deleted_at" timestamptz;
+
+CREATE INDEX ON "orders" ("deleted_at");
+CREATE INDEX ON "items" ("deleted_at");
@@ -0,0 +1,12 @@
+-- Entries outlive the orders they describe, so there is no foreign key.
+CREATE TABLE "audit_entries" (
+  "id" bigserial PRIMARY KEY,
+  "order_id" bigint NOT NULL,
+  "action" varchar NOT NULL,
+  "actor" varchar NOT NULL,
+  "request_id" varchar NOT NULL DEFAULT '',
+  "changes" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_entries" ("order_id");
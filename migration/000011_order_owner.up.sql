@@ -0,0 +1,5 @@
+-- Orders created before ownership existed have no owner; only staff and
+-- admins can reach them.
+ALTER TABLE "orders" ADD COLUMN "owner" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "orders" ("owner");
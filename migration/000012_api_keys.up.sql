@@ -0,0 +1,12 @@
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "prefix" varchar NOT NULL,
+  "hash" varchar NOT NULL UNIQUE,
+  "scopes" jsonb NOT NULL,
+  "created_by" varchar NOT NULL DEFAULT '',
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
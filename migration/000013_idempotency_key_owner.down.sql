@@ -0,0 +1,8 @@
+-- Keep one row per key so the old primary key can be restored.
+DELETE FROM "idempotency_keys" a
+USING "idempotency_keys" b
+WHERE a."key" = b."key" AND a."owner" > b."owner";
+
+ALTER TABLE "idempotency_keys" DROP CONSTRAINT "idempotency_keys_pkey";
+ALTER TABLE "idempotency_keys" DROP COLUMN IF EXISTS "owner";
+ALTER TABLE "idempotency_keys" ADD PRIMARY KEY ("key");
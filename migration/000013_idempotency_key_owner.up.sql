@@ -0,0 +1,7 @@
+-- Keys are scoped to whoever sent the request, so that callers can neither
+-- replay nor block each other's requests. Keys stored before then belong to
+-- no one.
+ALTER TABLE "idempotency_keys" ADD COLUMN "owner" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "idempotency_keys" DROP CONSTRAINT "idempotency_keys_pkey";
+ALTER TABLE "idempotency_keys" ADD PRIMARY KEY ("owner", "key");
@@ -0,0 +1,2 @@
+ALTER TABLE "idempotency_keys" DROP COLUMN IF EXISTS "expires_at";
+ALTER TABLE "idempotency_keys" DROP COLUMN IF EXISTS "locked_until";
@@ -0,0 +1,7 @@
+-- A key in progress is locked until locked_until; after that it is assumed
+-- its request died and the key can be taken over. Keys are forgotten after
+-- expires_at. Keys stored before then get a day.
+ALTER TABLE "idempotency_keys" ADD COLUMN "locked_until" timestamptz NOT NULL DEFAULT (now());
+ALTER TABLE "idempotency_keys" ADD COLUMN "expires_at" timestamptz NOT NULL DEFAULT (now() + interval '24 hours');
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
@@ -0,0 +1,4 @@
+-- Each request that claims a key gets a new lock token, and only the holder
+-- of the current token may complete or release the key. A request whose stale
+-- lock was taken over can't touch the key any more.
+ALTER TABLE "idempotency_keys" ADD COLUMN "lock_token" varchar(64) NOT NULL DEFAULT '';
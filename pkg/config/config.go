@@ -1,32 +1,70 @@
 package config
 
 import (
-	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	App      App
-	Database Database
+	App         App
+	Database    Database
+	Outbox      Outbox
+	Webhooks    Webhooks
+	Idempotency Idempotency
+	Auth        Auth
+	RateLimit   RateLimit
+	Tracing     Tracing
+	Log         Log
 }
 
 func NewConfig(v *viper.Viper) Config {
 	return Config{
-		App:      NewApp(v),
-		Database: NewDatabase(v),
+		App:         NewApp(v),
+		Database:    NewDatabase(v),
+		Outbox:      NewOutbox(v),
+		Webhooks:    NewWebhooks(v),
+		Idempotency: NewIdempotency(v),
+		Auth:        NewAuth(v),
+		RateLimit:   NewRateLimit(v),
+		Tracing:     NewTracing(v),
+		Log:         NewLog(v),
 	}
 }
 
+// App configures the HTTP server. The timeouts are those of http.Server.
+// On shutdown the server keeps serving, while reporting itself as not ready,
+// for ShutdownDelay, so load balancers can stop sending it traffic; then
+// in-flight requests get ShutdownTimeout to finish. Client IP addresses are
+// only read from X-Forwarded-For on requests from TrustedProxies, a list of
+// IPs and CIDRs that is empty by default.
 type App struct {
-	Port int
-	Host string
+	Port              int
+	Host              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	ShutdownDelay     time.Duration
+	ShutdownTimeout   time.Duration
+	TrustedProxies    []string
 }
 
 func NewApp(v *viper.Viper) App {
 	return App{
-		Port: v.GetInt("app.port"),
-		Host: v.GetString("app.host"),
+		Port:              v.GetInt("app.port"),
+		Host:              v.GetString("app.host"),
+		ReadTimeout:       v.GetDuration("app.read_timeout"),
+		ReadHeaderTimeout: v.GetDuration("app.read_header_timeout"),
+		WriteTimeout:      v.GetDuration("app.write_timeout"),
+		IdleTimeout:       v.GetDuration("app.idle_timeout"),
+		MaxHeaderBytes:    v.GetInt("app.max_header_bytes"),
+		ShutdownDelay:     v.GetDuration("app.shutdown_delay"),
+		ShutdownTimeout:   v.GetDuration("app.shutdown_timeout"),
+		TrustedProxies:    v.GetStringSlice("app.trusted_proxies"),
 	}
 }
 
@@ -38,17 +76,207 @@ type Database struct {
 	User     string
 	Timezone string
 	SslMode  string
+	// QueryTimeout bounds the database work done for a single request. Zero
+	// means no limit.
+	QueryTimeout time.Duration
+	// MigrationsPath is the directory of the migrations the schema must be
+	// up to date with for the service to be ready.
+	MigrationsPath string
+	// Queries slower than SlowQueryThreshold are logged as warnings. Their
+	// parameters are only logged if LogParams is set, as they hold customer
+	// data.
+	SlowQueryThreshold time.Duration
+	LogParams          bool
 }
 
 func NewDatabase(v *viper.Viper) Database {
 	return Database{
-		Name:     v.GetString("database.name"),
-		Host:     v.GetString("database.host"),
-		Port:     v.GetInt("database.port"),
-		Password: v.GetString("database.password"),
-		User:     v.GetString("database.user"),
-		Timezone: v.GetString("database.timezone"),
-		SslMode:  v.GetString("database.sslmode"),
+		Name:               v.GetString("database.name"),
+		Host:               v.GetString("database.host"),
+		Port:               v.GetInt("database.port"),
+		Password:           v.GetString("database.password"),
+		User:               v.GetString("database.user"),
+		Timezone:           v.GetString("database.timezone"),
+		SslMode:            v.GetString("database.sslmode"),
+		QueryTimeout:       v.GetDuration("database.query_timeout"),
+		MigrationsPath:     v.GetString("database.migrations_path"),
+		SlowQueryThreshold: v.GetDuration("database.slow_query_threshold"),
+		LogParams:          v.GetBool("database.log_params"),
+	}
+}
+
+// Outbox configures how domain events are published. Publisher is one of
+// "log", "webhook" or "memory". A claimed batch of events is reserved for at
+// most ClaimLease, which is also how long the events claimed by a dispatcher
+// that died wait before they are published.
+type Outbox struct {
+	Publisher      string
+	WebhookURL     string
+	WebhookTimeout time.Duration
+	PollInterval   time.Duration
+	BatchSize      int
+	ClaimLease     time.Duration
+}
+
+func NewOutbox(v *viper.Viper) Outbox {
+	return Outbox{
+		Publisher:      v.GetString("outbox.publisher"),
+		WebhookURL:     v.GetString("outbox.webhook_url"),
+		WebhookTimeout: v.GetDuration("outbox.webhook_timeout"),
+		PollInterval:   v.GetDuration("outbox.poll_interval"),
+		BatchSize:      v.GetInt("outbox.batch_size"),
+		ClaimLease:     v.GetDuration("outbox.claim_lease"),
+	}
+}
+
+// Webhooks configures the delivery of events to webhook subscriptions. A
+// delivery that failed MaxAttempts times is dead-lettered. Subscriptions may
+// only point at public addresses, unless AllowPrivateAddresses is set, e.g.
+// for local development. Like the outbox's, a claimed batch of deliveries is
+// reserved for at most ClaimLease.
+type Webhooks struct {
+	Timeout               time.Duration
+	PollInterval          time.Duration
+	BatchSize             int
+	MaxAttempts           int
+	AllowPrivateAddresses bool
+	ClaimLease            time.Duration
+}
+
+func NewWebhooks(v *viper.Viper) Webhooks {
+	return Webhooks{
+		Timeout:               v.GetDuration("webhooks.timeout"),
+		PollInterval:          v.GetDuration("webhooks.poll_interval"),
+		BatchSize:             v.GetInt("webhooks.batch_size"),
+		MaxAttempts:           v.GetInt("webhooks.max_attempts"),
+		AllowPrivateAddresses: v.GetBool("webhooks.allow_private_addresses"),
+		ClaimLease:            v.GetDuration("webhooks.claim_lease"),
+	}
+}
+
+// Idempotency configures how long idempotency keys are kept. A key is
+// forgotten TTL after it was first used. A key whose request is still in
+// progress after LockTimeout, e.g. because the process died, is handed to the
+// next request that retries it. Expired keys are deleted every
+// CleanupInterval.
+type Idempotency struct {
+	TTL             time.Duration
+	LockTimeout     time.Duration
+	CleanupInterval time.Duration
+}
+
+func NewIdempotency(v *viper.Viper) Idempotency {
+	return Idempotency{
+		TTL:             v.GetDuration("idempotency.ttl"),
+		LockTimeout:     v.GetDuration("idempotency.lock_timeout"),
+		CleanupInterval: v.GetDuration("idempotency.cleanup_interval"),
+	}
+}
+
+// Auth configures how API clients authenticate. Tokens are JWTs signed with
+// Algorithm, HS256 or RS256, and verified with Secret, the RSA public key in
+// PublicKeyFile, or the keys in the local JWKS file JWKSFile. Routes in
+// PublicPaths, such as health checks, are served without authentication.
+type Auth struct {
+	Enabled       bool
+	Algorithm     string
+	Secret        string
+	PublicKeyFile string
+	JWKSFile      string
+	Issuer        string
+	Audience      string
+	Leeway        time.Duration
+	PublicPaths   []string
+}
+
+func NewAuth(v *viper.Viper) Auth {
+	return Auth{
+		Enabled:       v.GetBool("auth.enabled"),
+		Algorithm:     v.GetString("auth.algorithm"),
+		Secret:        v.GetString("auth.secret"),
+		PublicKeyFile: v.GetString("auth.public_key_file"),
+		JWKSFile:      v.GetString("auth.jwks_file"),
+		Issuer:        v.GetString("auth.issuer"),
+		Audience:      v.GetString("auth.audience"),
+		Leeway:        v.GetDuration("auth.leeway"),
+		PublicPaths:   v.GetStringSlice("auth.public_paths"),
+	}
+}
+
+// RateLimit configures per-client rate limits. Clients are told apart by API
+// key, user, or IP address, in that order. Every route shares the Default
+// allowance, except those listed in Routes, which get one of their own.
+// Before they are authenticated, requests are also limited to PerIP for each
+// IP address, so that guessing credentials is throttled as well.
+type RateLimit struct {
+	Enabled bool
+	PerIP   Rate
+	Default Rate
+	Routes  []RouteRate
+}
+
+// Rate allows Requests per Period, in bursts of up to Burst requests.
+type Rate struct {
+	Requests int           `mapstructure:"requests"`
+	Period   time.Duration `mapstructure:"period"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+// RouteRate is the rate of the route with the given method and path, as it
+// is registered with the router, e.g. "/orders/:id".
+type RouteRate struct {
+	Method string `mapstructure:"method"`
+	Path   string `mapstructure:"path"`
+	Rate   `mapstructure:",squash"`
+}
+
+func NewRateLimit(v *viper.Viper) RateLimit {
+	rateLimit := RateLimit{Enabled: v.GetBool("rate_limit.enabled")}
+
+	if err := v.UnmarshalKey("rate_limit.per_ip", &rateLimit.PerIP); err != nil {
+		fatal("Load rate_limit.per_ip error", err)
+	}
+	if err := v.UnmarshalKey("rate_limit.default", &rateLimit.Default); err != nil {
+		fatal("Load rate_limit.default error", err)
+	}
+	if err := v.UnmarshalKey("rate_limit.routes", &rateLimit.Routes); err != nil {
+		fatal("Load rate_limit.routes error", err)
+	}
+
+	return rateLimit
+}
+
+// Tracing configures where spans go. Exporter is "otlp", which sends them to
+// the OTLP/HTTP collector at Endpoint, "stdout" or "none". SampleRatio is
+// the share of traces started here that are recorded; traces started by a
+// caller follow the caller's decision.
+type Tracing struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	ServiceName string
+	SampleRatio float64
+}
+
+func NewTracing(v *viper.Viper) Tracing {
+	return Tracing{
+		Exporter:    v.GetString("tracing.exporter"),
+		Endpoint:    v.GetString("tracing.endpoint"),
+		Insecure:    v.GetBool("tracing.insecure"),
+		ServiceName: v.GetString("tracing.service_name"),
+		SampleRatio: v.GetFloat64("tracing.sample_ratio"),
+	}
+}
+
+// Log configures logging. Level is "debug", "info", "warn" or "error"; at
+// "debug" every SQL statement is logged.
+type Log struct {
+	Level string
+}
+
+func NewLog(v *viper.Viper) Log {
+	return Log{
+		Level: v.GetString("log.level"),
 	}
 }
 
@@ -58,8 +286,15 @@ func LoadConfig(path string) Config {
 
 	err := v.ReadInConfig()
 	if err != nil {
-		log.Fatal("Load config error: ", err.Error())
+		fatal("Load config error", err)
 	}
 
 	return NewConfig(v)
 }
+
+// fatal reports an unusable config and exits. The config is read before
+// logging is set up, so this goes to the default logger.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
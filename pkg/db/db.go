@@ -2,12 +2,13 @@ package db
 
 import (
 	"fmt"
+	"log/slog"
 
 	cfg "simple-order-go/pkg/config"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 const DSN = "host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s"
@@ -27,7 +28,7 @@ func InitDB(d cfg.Database) (*gorm.DB, error) {
 		&gorm.Config{
 			SkipDefaultTransaction: true,
 			PrepareStmt:            true,
-			Logger:                 logger.Default.LogMode(logger.Info),
+			Logger:                 NewLogger(slog.Default(), d.SlowQueryThreshold, d.LogParams),
 		},
 	)
 
@@ -35,5 +36,24 @@ func InitDB(d cfg.Database) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	err = db.Use(MetricsPlugin{DBName: d.Name, Registerer: prometheus.DefaultRegisterer})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Use(TracingPlugin{DBName: d.Name})
+	if err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
+
+// Close closes the connection pool behind db.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func openDryRun(t *testing.T, logger *Logger) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &record))
+		lines = append(lines, record)
+	}
+	return lines
+}
+
+func TestLoggerRedactsParams(t *testing.T) {
+	testCases := []struct {
+		name      string
+		logParams bool
+		sql       string
+	}{
+		{name: "Redacted", sql: `SELECT * FROM "orders" WHERE customer = '[redacted]'`},
+		{name: "WithParams", logParams: true, sql: `SELECT * FROM "orders" WHERE customer = 'secret customer'`},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			db := openDryRun(t, NewLogger(logger, time.Second, tc.logParams))
+			db.Where("customer = ?", "secret customer").Find(&[]order{})
+
+			lines := logLines(t, &buf)
+			require.Equal(t, 1, len(lines))
+			require.Equal(t, "query", lines[0]["msg"])
+			require.Equal(t, "DEBUG", lines[0]["level"])
+			require.Equal(t, tc.sql, lines[0]["sql"])
+		})
+	}
+}
+
+func TestLoggerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})), 10*time.Millisecond, false)
+
+	trace := func(elapsed time.Duration, err error) {
+		logger.Trace(context.Background(), time.Now().Add(-elapsed), func() (string, int64) {
+			return `SELECT * FROM "orders"`, 1
+		}, err)
+	}
+
+	trace(time.Millisecond, nil)
+	trace(time.Millisecond, gorm.ErrRecordNotFound)
+	trace(time.Second, nil)
+	trace(time.Millisecond, errors.New("connection reset"))
+
+	// Fast queries are only logged at debug level, and a missing record
+	// isn't a failure.
+	lines := logLines(t, &buf)
+	require.Equal(t, 2, len(lines))
+	require.Equal(t, "slow query", lines[0]["msg"])
+	require.Equal(t, "WARN", lines[0]["level"])
+	require.Equal(t, "query failed", lines[1]["msg"])
+	require.Equal(t, "ERROR", lines[1]["level"])
+	require.Equal(t, "connection reset", lines[1]["error"])
+}
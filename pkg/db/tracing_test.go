@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type order struct {
+	ID       int64
+	Customer string
+}
+
+func TestTracingPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	// A dry run builds the statements without a database to send them to.
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(TracingPlugin{DBName: "orders"}))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	db.WithContext(ctx).Where("customer = ?", "secret customer").Find(&[]order{})
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Equal(t, 2, len(spans))
+
+	query := spans[0]
+	require.Equal(t, "gorm.query", query.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+	require.Contains(t, query.Attributes(), semconv.DBSQLTable("orders"))
+	require.Contains(t, query.Attributes(), attribute.String("db.statement", `SELECT * FROM "orders" WHERE customer = $1`))
+}